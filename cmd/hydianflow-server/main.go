@@ -17,7 +17,9 @@ import (
 	"github.com/AJMerr/hydianflow/internal/githubapi"
 	"github.com/AJMerr/hydianflow/internal/githubhttp"
 	"github.com/AJMerr/hydianflow/internal/projects"
+	"github.com/AJMerr/hydianflow/internal/realtime"
 	"github.com/AJMerr/hydianflow/internal/tasks"
+	"github.com/AJMerr/hydianflow/internal/templates"
 	"github.com/AJMerr/hydianflow/internal/users"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -61,6 +63,15 @@ func main() {
 		log.Printf("DEV_AUTH enabled; default user id = %d", devUserID)
 	}
 
+	// Background work: live board updates (Postgres LISTEN/NOTIFY -> SSE),
+	// the webhook delivery worker and the recurring task scheduler
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	hub := realtime.NewHub(database.DSN())
+	go hub.Run(bgCtx)
+	go ghwebhook.NewWorker(db).Run(bgCtx)
+	go templates.NewScheduler(db).Run(bgCtx)
+
 	// Middleware and Router
 	r := chi.NewRouter()
 	r.Use(middleware.RequestID)
@@ -118,6 +129,16 @@ func main() {
 		log.Fatalf("oauth init: %v", oerr)
 	}
 
+	// Optional GitHub App; repositories it is installed on are accessed with
+	// installation tokens on behalf of users who can reach them themselves
+	ghApp, aerr := githubapi.NewAppFromEnv()
+	if aerr != nil {
+		log.Fatalf("github app init: %v", aerr)
+	}
+	if ghApp != nil {
+		log.Printf("GitHub App mode enabled; app id = %d", ghApp.ID)
+	}
+
 	r.Route("/api/v1", func(api chi.Router) {
 		// Public auth endpoints
 		api.Group(func(pub chi.Router) {
@@ -135,12 +156,18 @@ func main() {
 			priv.Use(auth.FromSession(sessions.Manager))
 			priv.Use(sessionAuth)
 
-			ghsvc := &githubapi.Service{DB: db}
-			priv.Mount("/github", githubhttp.Router(ghsvc))
+			ghsvc := &githubapi.Service{DB: db, App: ghApp, BaseURL: os.Getenv("GITHUB_API_URL")}
+			priv.Mount("/github", githubhttp.Router(ghsvc, webhookURL()))
 
 			priv.Mount("/users", users.Router(db))
-			priv.Mount("/projects", projects.Router(db))
-			priv.Mount("/tasks", tasks.Router(db))
+			priv.Mount("/projects", projects.Router(db, hub, ghsvc))
+			priv.Mount("/tasks", tasks.Router(db, ghsvc, os.Getenv("BRANCH_NAME_TEMPLATE")))
+
+			// Operator-only endpoints, restricted to ADMIN_GITHUB_LOGINS
+			priv.Route("/admin", func(adm chi.Router) {
+				adm.Use(auth.RequireAdmin(db.DB, getListEnv("ADMIN_GITHUB_LOGINS", nil)))
+				adm.Mount("/webhooks", ghwebhook.AdminRouter(db))
+			})
 
 			priv.Get("/dev", func(w http.ResponseWriter, r *http.Request) {
 				uid := sessions.Manager.GetInt(r.Context(), "user_id")
@@ -160,6 +187,8 @@ func main() {
 	})
 
 	addr := getEnv("HTTP_ADDR", ":8080")
+	// WriteTimeout bounds ordinary requests; the SSE handler clears its own
+	// write deadline so event streams can stay open.
 	srv := &http.Server{
 		Addr:         addr,
 		Handler:      r,
@@ -167,6 +196,8 @@ func main() {
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
+	// Close open event streams so Shutdown isn't held up by them
+	srv.RegisterOnShutdown(stopBackground)
 
 	// Starts server
 	go func() {
@@ -213,3 +244,16 @@ func getListEnv(key string, def []string) []string {
 	}
 	return out
 }
+
+// webhookURL is the public address GitHub delivers webhooks to. It defaults
+// to the webhook route under the OAuth redirect base, which is the server's
+// public URL.
+func webhookURL() string {
+	if v := strings.TrimSpace(os.Getenv("GITHUB_WEBHOOK_URL")); v != "" {
+		return v
+	}
+	if base := strings.TrimSpace(os.Getenv("OAUTH_REDIRECT_BASE_URL")); base != "" {
+		return strings.TrimRight(base, "/") + "/api/v1/webhooks/github"
+	}
+	return ""
+}
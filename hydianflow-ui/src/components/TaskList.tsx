@@ -126,7 +126,6 @@ export function EditableTaskCard({
   const [ebranch, setEBranch] = useState(t.branch_hint ?? "");
   const [eassignee, setEAssignee] = useState<number | null | undefined>(t.assignee_id ?? null);
   const [eRepoConfirmed, setERepoConfirmed] = useState(Boolean(erepo));
-  const [etag, setETag] = useState<"feature" | "feature_request" | "issue" | "">(t.tag ?? "");
   const edit = useEditTask(() => setOpen(false));
 
   const assigneeName = useMemo(() => {
@@ -246,19 +245,6 @@ export function EditableTaskCard({
                     enabled={eRepoConfirmed}
                   />
                 </div>
-                <div className="grid gap-1.5">
-                  <label className="text-sm font-medium">Tag</label>
-                  <select
-                    className="h-9 rounded-md border bg-background px-2 text-sm"
-                    value={etag}
-                    onChange={(e) => setETag(e.target.value as any)}
-                  >
-                    <option value="">None</option>
-                    <option value="feature">Feature</option>
-                    <option value="feature_request">Feature Request</option>
-                    <option value="issue">Issue</option>
-                  </select>
-                </div>
               </div>
               <DialogFooter className="gap-2">
                 <Button variant="ghost" onClick={() => setOpen(false)}>Cancel</Button>
@@ -271,7 +257,6 @@ export function EditableTaskCard({
                       repo_full_name: erepo.trim() || null,
                       branch_hint: ebranch.trim() || null,
                       assignee_id: eassignee ?? null,
-                      tag: etag ? etag : null,
                     })
                   }
                   disabled={edit.isPending || !etitle.trim()}
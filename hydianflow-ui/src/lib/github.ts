@@ -41,3 +41,9 @@ export function useBranchSearch(repoFullName: string, enabled: boolean) {
 }
 
 
+
+export type RepoWebhook = { repo_full_name: string; hook_id: number; url: string; created: boolean };
+
+export function installRepoWebhook(repoFullName: string) {
+  return api.post<RepoWebhook>(`/api/v1/github/webhooks`, { repo_full_name: repoFullName });
+}
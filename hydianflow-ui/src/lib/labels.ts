@@ -0,0 +1,24 @@
+import { api } from "@/lib/api";
+import type { Label } from "@/lib/tasks";
+
+export type { Label };
+
+export function listProjectLabels(projectId: number) {
+  return api.get<Label[]>(`/api/v1/projects/${projectId}/labels`);
+}
+
+export function createProjectLabel(projectId: number, body: { name: string; color?: string }) {
+  return api.post<Label>(`/api/v1/projects/${projectId}/labels`, body);
+}
+
+export function updateProjectLabel(
+  projectId: number,
+  labelId: number,
+  body: { name?: string; color?: string },
+) {
+  return api.patch<Label>(`/api/v1/projects/${projectId}/labels/${labelId}`, body);
+}
+
+export function deleteProjectLabel(projectId: number, labelId: number) {
+  return api.delete<{ ok: string }>(`/api/v1/projects/${projectId}/labels/${labelId}`);
+}
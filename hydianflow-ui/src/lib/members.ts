@@ -5,8 +5,27 @@ export type Member = {
   name: string;
   avatar_url?: string;
   github_login?: string;
+  role?: "owner" | "admin" | "member" | "viewer";
 };
 
 export function listProjectMembers(projectId: number) {
   return api.get<Member[]>(`/api/v1/projects/${projectId}/members`);
 }
+
+export function addProjectMember(
+  projectId: number,
+  body: { user_id?: number; github_login?: string; role?: Member["role"] },
+) {
+  return api.post<Member>(`/api/v1/projects/${projectId}/members`, body);
+}
+
+export function updateProjectMember(projectId: number, userId: number, role: Member["role"]) {
+  return api.patch<{ user_id: number; role: string }>(
+    `/api/v1/projects/${projectId}/members/${userId}`,
+    { role },
+  );
+}
+
+export function removeProjectMember(projectId: number, userId: number) {
+  return api.delete<{ ok: string }>(`/api/v1/projects/${projectId}/members/${userId}`);
+}
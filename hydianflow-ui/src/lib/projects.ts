@@ -1,5 +1,18 @@
 import { api } from "@/lib/api";
 
+export type TaskCounts = {
+  total: number;
+  by_status: Record<string, number>;
+  by_category: Record<"todo" | "doing" | "done", number>;
+  overdue: number;
+  last_activity_at: string | null;
+};
+
+export type ProjectStats = TaskCounts & {
+  // The project plus every descendant the caller can see
+  rollup: TaskCounts & { projects: number };
+};
+
 export type Project = {
   id: number;
   owner_id: number;
@@ -10,22 +23,34 @@ export type Project = {
 
   parent_id?: number | null;
   has_children?: boolean;
+  sync_issue_state?: boolean;
+  stats?: ProjectStats;
 };
 
 export interface ProjectUpdateRequest {
   name?: string;
   description?: string | null;
   parent_id?: number | null;
+  blocked_policy?: "refuse" | "warn";
+  sync_issue_state?: boolean;
 }
 
 export async function listProjects() {
   return api.get<Project[]>("/api/v1/projects");
 }
 
+export async function listProjectsWithStats() {
+  return api.get<Project[]>("/api/v1/projects?include=stats");
+}
+
 export async function getProject(id: number) {
   return api.get<Project>(`/api/v1/projects/${id}`);
 }
 
+export async function getProjectWithStats(id: number) {
+  return api.get<Project>(`/api/v1/projects/${id}?include=stats`);
+}
+
 export async function createProject(body: {
   name: string;
   description?: string;
@@ -41,3 +66,11 @@ export function updateProject(id: number, body: ProjectUpdateRequest) {
 export async function deleteProject(id: number) {
   return api.delete<{ ok: string }>(`/api/v1/projects/${id}`);
 }
+
+export function importGitHubIssues(id: number, repoFullName: string, state: "open" | "all" = "open") {
+  const q = `repo_full_name=${encodeURIComponent(repoFullName)}&state=${state}`;
+  return api.post<{ imported: number; skipped: number; labels_skipped?: string[]; task_ids: number[] }>(
+    `/api/v1/projects/${id}/import/github-issues?${q}`,
+    {},
+  );
+}
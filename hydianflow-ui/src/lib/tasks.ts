@@ -2,17 +2,40 @@ import { api } from "./api";
 
 export type Status = "todo" | "in_progress" | "done";
 
+export interface Label {
+  id: number;
+  name: string;
+  color: string;
+}
+
 export interface Task {
   id: number;
   title: string;
   description?: string | null;
   tag?: "feature" | "feature_request" | "issue" | null;
+  labels?: Label[];
   status: Status;
   position: number;
   creator_id: number;
   assignee_id?: number | null;
   repo_full_name?: string | null;
   branch_hint?: string | null;
+  pr_number?: number | null;
+  pr_url?: string | null;
+  pr_state?: "open" | "closed" | "merged" | null;
+  pr_draft?: boolean;
+  issue_number?: number;
+  parent_task_id?: number;
+  auto_complete?: boolean;
+  subtasks?: { done: number; total: number };
+  checklist?: { done: number; total: number };
+  is_blocked?: boolean;
+  blocked_by?: number[];
+  warnings?: string[];
+  due_at?: string | null;
+  priority?: "low" | "medium" | "high" | "urgent" | null;
+  estimate_points?: number | null;
+  template_id?: number;
   project_id?: number | null;
 
   started_at?: string | null;
@@ -23,7 +46,7 @@ export interface Task {
 
 export interface TaskList {
   items: Task[];
-  next_cursor: number;
+  next_cursor: string;
 }
 
 export interface TaskCreateRequest {
@@ -36,6 +59,7 @@ export interface TaskCreateRequest {
   branch_hint?: string;
   project_id?: number;
   assignee_id?: number | null;
+  label_ids?: number[];
 }
 
 export interface TaskUpdateRequest {
@@ -48,6 +72,7 @@ export interface TaskUpdateRequest {
   repo_full_name?: string | null;
   branch_hint?: string | null;
   project_id?: number | null;
+  label_ids?: number[];
 }
 
 function qs(params: Record<string, any>): string {
@@ -61,8 +86,11 @@ function qs(params: Record<string, any>): string {
 export function getAllTasks(opts: {
   status?: Status;
   limit?: number;
-  cursor?: number;
+  cursor?: string;
+  sort?: string;
   project_id?: number;
+  label_ids?: string;
+  label_match?: "any" | "all";
 } = {}) {
   return api.get<TaskList>(`/api/v1/tasks${qs(opts)}`);
 }
@@ -88,3 +116,20 @@ export const moveTask = (id: number, status: Status | "completed") =>
 
 export const reorderTask = (id: number, position: number) =>
   updateTask(id, { position });
+
+export interface TaskMoveRequest {
+  status?: string;
+  before_id?: number;
+  after_id?: number;
+}
+
+export const placeTask = (id: number, body: TaskMoveRequest) =>
+  api.post<Task>(`/api/v1/tasks/${id}/move`, body);
+
+export function createTaskBranch(id: number, body: { name?: string; base?: string } = {}) {
+  return api.post<{ branch: string; sha: string; task: Task }>(`/api/v1/tasks/${id}/branch`, body);
+}
+
+export function createTaskPullRequest(id: number, body: { base?: string; draft?: boolean } = {}) {
+  return api.post<{ number: number; url: string; task: Task }>(`/api/v1/tasks/${id}/pull-request`, body);
+}
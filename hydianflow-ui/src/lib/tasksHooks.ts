@@ -15,7 +15,7 @@ export function useTasksColumn(status: Status, projectId?: number) {
   });
   return {
     items: q.data?.items ?? [],
-    nextCursor: q.data?.next_cursor ?? 0,
+    nextCursor: q.data?.next_cursor ?? "",
     isLoading: q.isLoading,
     isError: q.isError,
     refetch: q.refetch,
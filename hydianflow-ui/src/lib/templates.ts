@@ -0,0 +1,54 @@
+import { api } from "@/lib/api";
+
+export type TaskTemplate = {
+  id: number;
+  project_id: number;
+  creator_id: number;
+  title: string;
+  description: string;
+  assignee_id?: number | null;
+  priority?: "low" | "medium" | "high" | "urgent" | null;
+  estimate_points?: number | null;
+  label_ids?: number[] | null;
+  due_in_days?: number | null;
+  // "daily", "weekly", "monthly" or a five-field cron expression
+  recurrence: string;
+  timezone: string;
+  enabled: boolean;
+  next_run_at: string;
+  last_run_at?: string | null;
+  created_at: string;
+  updated_at: string;
+};
+
+export type TaskTemplateRequest = Partial<
+  Pick<
+    TaskTemplate,
+    | "title"
+    | "description"
+    | "assignee_id"
+    | "priority"
+    | "estimate_points"
+    | "label_ids"
+    | "due_in_days"
+    | "recurrence"
+    | "timezone"
+    | "enabled"
+  >
+>;
+
+export function listProjectTemplates(projectId: number) {
+  return api.get<TaskTemplate[]>(`/api/v1/projects/${projectId}/templates`);
+}
+
+export function createProjectTemplate(projectId: number, body: TaskTemplateRequest) {
+  return api.post<TaskTemplate>(`/api/v1/projects/${projectId}/templates`, body);
+}
+
+export function updateProjectTemplate(projectId: number, templateId: number, body: TaskTemplateRequest) {
+  return api.patch<TaskTemplate>(`/api/v1/projects/${projectId}/templates/${templateId}`, body);
+}
+
+export function deleteProjectTemplate(projectId: number, templateId: number) {
+  return api.delete<{ ok: string }>(`/api/v1/projects/${projectId}/templates/${templateId}`);
+}
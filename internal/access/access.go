@@ -0,0 +1,114 @@
+package access
+
+import (
+	"errors"
+
+	"github.com/AJMerr/hydianflow/internal/database"
+	"gorm.io/gorm"
+)
+
+var (
+	// Project does not exist or the user has no role in it
+	ErrNotFound = errors.New("project not found")
+	// User is a member but their role is too low for the action
+	ErrForbidden = errors.New("insufficient project role")
+)
+
+var roleRank = map[database.ProjectRole]int{
+	database.ProjectRoleViewer: 1,
+	database.ProjectRoleMember: 2,
+	database.ProjectRoleAdmin:  3,
+	database.ProjectRoleOwner:  4,
+}
+
+// AtLeast reports whether role grants everything min does.
+func AtLeast(role, min database.ProjectRole) bool {
+	return roleRank[role] >= roleRank[min] && roleRank[role] > 0
+}
+
+// ValidRole reports whether s is a known project role.
+func ValidRole(s string) bool {
+	_, ok := roleRank[database.ProjectRole(s)]
+	return ok
+}
+
+// RoleFor resolves the user's role in a project. The project owner is always
+// treated as owner, even if the membership row is missing.
+func RoleFor(db *gorm.DB, projectID, userID uint) (database.ProjectRole, error) {
+	_, role, err := load(db, projectID, userID)
+	return role, err
+}
+
+// Require loads the project and checks the user holds at least min.
+func Require(db *gorm.DB, projectID, userID uint, min database.ProjectRole) (database.Project, database.ProjectRole, error) {
+	p, role, err := load(db, projectID, userID)
+	if err != nil {
+		return database.Project{}, "", err
+	}
+	if !AtLeast(role, min) {
+		return database.Project{}, role, ErrForbidden
+	}
+	return p, role, nil
+}
+
+func load(db *gorm.DB, projectID, userID uint) (database.Project, database.ProjectRole, error) {
+	var p database.Project
+	if err := db.Where("id = ?", projectID).First(&p).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return p, "", ErrNotFound
+		}
+		return p, "", err
+	}
+	if p.OwnerID == userID {
+		return p, database.ProjectRoleOwner, nil
+	}
+
+	var m database.ProjectMember
+	if err := db.Where("project_id = ? AND user_id = ?", projectID, userID).First(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return p, "", ErrNotFound
+		}
+		return p, "", err
+	}
+	return p, m.Role, nil
+}
+
+// ProjectIDs is a subquery of every project id the user can see.
+func ProjectIDs(db *gorm.DB, userID uint) *gorm.DB {
+	db = db.Session(&gorm.Session{NewDB: true})
+	return db.Model(&database.Project{}).
+		Select("id").
+		Where("owner_id = ? OR id IN (?)", userID,
+			db.Model(&database.ProjectMember{}).Select("project_id").Where("user_id = ?", userID))
+}
+
+// Tasks scopes a task query to rows the user can see: personal tasks they
+// created, plus every task in a project they belong to.
+func Tasks(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Where("((tasks.project_id IS NULL AND tasks.creator_id = ?) OR tasks.project_id IN (?))",
+		userID, ProjectIDs(db, userID))
+}
+
+// TaskRole resolves the user's effective role on a task. Personal tasks only
+// grant access to their creator, who is treated as owner.
+func TaskRole(db *gorm.DB, t database.Task, userID uint) (database.ProjectRole, error) {
+	if t.ProjectID == nil {
+		if t.CreatorID == userID {
+			return database.ProjectRoleOwner, nil
+		}
+		return "", ErrNotFound
+	}
+	return RoleFor(db, *t.ProjectID, userID)
+}
+
+// RequireTask checks the user holds at least min on the task.
+func RequireTask(db *gorm.DB, t database.Task, userID uint, min database.ProjectRole) (database.ProjectRole, error) {
+	role, err := TaskRole(db, t, userID)
+	if err != nil {
+		return "", err
+	}
+	if !AtLeast(role, min) {
+		return role, ErrForbidden
+	}
+	return role, nil
+}
@@ -0,0 +1,21 @@
+package access
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/AJMerr/hydianflow/internal/utils"
+)
+
+// WriteError maps an access error to a JSON error response. notFound is the
+// message used when the resource is missing or hidden from the user.
+func WriteError(w http.ResponseWriter, err error, notFound string) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		utils.Error(w, http.StatusNotFound, "not_found", notFound)
+	case errors.Is(err, ErrForbidden):
+		utils.Error(w, http.StatusForbidden, "forbidden", "insufficient project role")
+	default:
+		utils.Error(w, http.StatusInternalServerError, "db_access", "could not check access")
+	}
+}
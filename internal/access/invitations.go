@@ -0,0 +1,56 @@
+package access
+
+import (
+	"time"
+
+	"github.com/AJMerr/hydianflow/internal/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ClaimInvitations converts every open invitation for the GitHub login into a
+// project membership for the user. Existing memberships keep their role.
+func ClaimInvitations(db *gorm.DB, userID uint, login string) (int, error) {
+	if login == "" {
+		return 0, nil
+	}
+	now := time.Now().UTC()
+	claimed := 0
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var invs []database.ProjectInvitation
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("LOWER(github_login) = LOWER(?) AND accepted_at IS NULL AND expires_at > ?", login, now).
+			Find(&invs).Error; err != nil {
+			return err
+		}
+		for _, inv := range invs {
+			if err := AcceptInvitation(tx, inv, userID, now); err != nil {
+				return err
+			}
+			claimed++
+		}
+		return nil
+	})
+	return claimed, err
+}
+
+// AcceptInvitation adds the user to the invitation's project and marks the
+// invitation accepted. Callers check expiry and login beforehand.
+func AcceptInvitation(tx *gorm.DB, inv database.ProjectInvitation, userID uint, now time.Time) error {
+	if err := tx.
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&database.ProjectMember{
+			ProjectID: inv.ProjectID,
+			UserID:    userID,
+			Role:      inv.Role,
+		}).Error; err != nil {
+		return err
+	}
+	return tx.Model(&database.ProjectInvitation{}).
+		Where("id = ?", inv.ID).
+		Updates(map[string]any{
+			"accepted_at":    now,
+			"accepted_by_id": userID,
+			"updated_at":     now,
+		}).Error
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/utils"
+	"gorm.io/gorm"
+)
+
+// RequireAdmin only lets through users whose GitHub login is in logins
+// (case-insensitive). With an empty list every request is refused.
+func RequireAdmin(db *gorm.DB, logins []string) func(http.Handler) http.Handler {
+	allowed := make(map[string]struct{}, len(logins))
+	for _, l := range logins {
+		if l = strings.ToLower(strings.TrimSpace(l)); l != "" {
+			allowed[l] = struct{}{}
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			uid, ok := UserIDFromCtx(r.Context())
+			if !ok || uid == 0 {
+				utils.Error(w, http.StatusUnauthorized, "unauthorized", "auth required")
+				return
+			}
+			var u database.User
+			if err := db.Select("id", "github_login").First(&u, uid).Error; err != nil {
+				utils.Error(w, http.StatusForbidden, "forbidden", "admin only")
+				return
+			}
+			if _, ok := allowed[strings.ToLower(u.GitHubLogin)]; !ok {
+				utils.Error(w, http.StatusForbidden, "forbidden", "admin only")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
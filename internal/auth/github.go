@@ -5,11 +5,13 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/AJMerr/hydianflow/internal/access"
 	"github.com/AJMerr/hydianflow/internal/database"
 	"github.com/alexedwards/scs/v2"
 	gh "github.com/google/go-github/v66/github"
@@ -36,8 +38,9 @@ func NewGitHubOAuth(db *gorm.DB, sessions *scs.SessionManager) (*OAuthHandler, e
 		ClientID:     clientID,
 		ClientSecret: secret,
 		Endpoint:     oauthgithub.Endpoint,
-		Scopes:       []string{"read:user", "user:email"},
-		RedirectURL:  strings.TrimRight(base, "/") + "/api/v1/auth/github/callback",
+		// repo lets Hydianflow create branches and pull requests for tasks
+		Scopes:      []string{"read:user", "user:email", "repo"},
+		RedirectURL: strings.TrimRight(base, "/") + "/api/v1/auth/github/callback",
 	}
 	return &OAuthHandler{DB: db, Sessions: sessions, Conf: conf}, nil
 }
@@ -172,6 +175,13 @@ func (h *OAuthHandler) Callback(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Pending project invitations for this login become memberships
+	if n, err := access.ClaimInvitations(h.DB, u.ID, u.GitHubLogin); err != nil {
+		log.Printf("claim invitations for user %d: %v", u.ID, err)
+	} else if n > 0 {
+		log.Printf("user %d joined %d project(s) from invitations", u.ID, n)
+	}
+
 	// Set session user_id
 	h.Sessions.Put(ctx, "user_id", int(u.ID))
 
@@ -13,6 +13,24 @@ const (
 	TaskStatusCompleted  TaskStatus = "completed"
 )
 
+type TaskPriority string
+
+const (
+	TaskPriorityLow    TaskPriority = "low"
+	TaskPriorityMedium TaskPriority = "medium"
+	TaskPriorityHigh   TaskPriority = "high"
+	TaskPriorityUrgent TaskPriority = "urgent"
+)
+
+type ProjectRole string
+
+const (
+	ProjectRoleOwner  ProjectRole = "owner"
+	ProjectRoleAdmin  ProjectRole = "admin"
+	ProjectRoleMember ProjectRole = "member"
+	ProjectRoleViewer ProjectRole = "viewer"
+)
+
 type User struct {
 	gorm.Model
 	GitHubID             int64      `gorm:"column:github_id;uniqueIndex" json:"github_id"`
@@ -42,9 +60,22 @@ type Task struct {
 	RepoName    *string    `gorm:"column:repo_full_name;index" json:"repo_full_name"`
 	BranchHint  *string    `gorm:"column:branch_hint;index" json:"branch_hint"`
 	PRNumber    *int       `gorm:"index" json:"pr_number"`
-	ProjectID   *uint      `gorm:"index" json:"project_id,omitempty"`
-	StartedAt   *time.Time `json:"started_at"`
-	CompletedAt *time.Time `json:"completed_at"`
+	PRURL       *string    `gorm:"column:pr_url" json:"pr_url"`
+	PRState     *string    `gorm:"column:pr_state" json:"pr_state"`
+	PRDraft     bool       `gorm:"column:pr_draft;not null;default:false" json:"pr_draft"`
+	// IssueNumber links a task imported from an issue in RepoName
+	IssueNumber    *int          `json:"issue_number"`
+	ProjectID      *uint         `gorm:"index" json:"project_id,omitempty"`
+	ParentTaskID   *uint         `gorm:"index" json:"parent_task_id,omitempty"`
+	AutoComplete   bool          `gorm:"column:auto_complete;not null;default:false" json:"auto_complete"`
+	DueAt          *time.Time    `json:"due_at"`
+	Priority       *TaskPriority `gorm:"type:text" json:"priority"`
+	EstimatePoints *int          `json:"estimate_points"`
+	// Set on tasks created by a recurring template, for the run that made them
+	TemplateID    *uint      `gorm:"index" json:"template_id,omitempty"`
+	TemplateRunAt *time.Time `json:"template_run_at,omitempty"`
+	StartedAt     *time.Time `json:"started_at"`
+	CompletedAt   *time.Time `json:"completed_at"`
 }
 
 type Project struct {
@@ -57,10 +88,187 @@ type Project struct {
 	ParentID *uint     `gorm:"index" json:"parent_id,omitempty"`
 	Children []Project `gorm:"foreignKey:ParentID" json:"-"`
 	Tasks    []Task    `gorm:"foreignKey:ProjectID" json:"-"`
+
+	BlockedPolicy BlockedPolicy `gorm:"type:text;not null;default:refuse" json:"blocked_policy"`
+	// SyncIssueState closes and reopens linked GitHub issues with their tasks
+	SyncIssueState bool `gorm:"not null" json:"sync_issue_state"`
+}
+
+// BlockedPolicy decides what happens when a blocked task is started.
+type BlockedPolicy string
+
+const (
+	BlockedPolicyRefuse BlockedPolicy = "refuse"
+	BlockedPolicyWarn   BlockedPolicy = "warn"
+)
+
+// TaskDependency records that BlockerID must be done before BlockedID can start.
+type TaskDependency struct {
+	BlockerID   uint      `gorm:"primaryKey" json:"blocker_id"`
+	BlockedID   uint      `gorm:"primaryKey;index" json:"blocked_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	CreatedByID *uint     `json:"created_by_id,omitempty"`
 }
 
 type ProjectMember struct {
-	ProjectID uint   `gorm:"primaryKey"`
-	UserID    uint   `gorm:"primaryKey"`
-	Role      string `gorm:"type:varchar(16);not null; default:member"`
+	ProjectID uint        `gorm:"primaryKey"`
+	UserID    uint        `gorm:"primaryKey"`
+	Role      ProjectRole `gorm:"type:varchar(16);not null; default:member"`
+}
+
+type ProjectInvitation struct {
+	gorm.Model
+	ProjectID    uint        `gorm:"index;not null" json:"project_id"`
+	Project      Project     `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	GitHubLogin  string      `gorm:"column:github_login;not null" json:"github_login"`
+	Role         ProjectRole `gorm:"type:varchar(16);not null;default:member" json:"role"`
+	Token        string      `gorm:"uniqueIndex;not null" json:"-"`
+	InvitedByID  uint        `gorm:"not null" json:"invited_by_id"`
+	ExpiresAt    time.Time   `gorm:"not null" json:"expires_at"`
+	AcceptedAt   *time.Time  `json:"accepted_at"`
+	AcceptedByID *uint       `json:"accepted_by_id"`
+}
+
+type TaskEvent struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt       time.Time `json:"created_at"`
+	TaskID          uint      `gorm:"index;not null" json:"task_id"`
+	ActorUserID     *uint     `gorm:"index" json:"actor_user_id"`
+	ActorDeliveryID *string   `gorm:"column:actor_delivery_id" json:"actor_delivery_id"`
+	Field           string    `gorm:"type:text;not null" json:"field"`
+	OldValue        *string   `gorm:"type:text" json:"old_value"`
+	NewValue        *string   `gorm:"type:text" json:"new_value"`
+}
+
+type TaskComment struct {
+	gorm.Model
+	TaskID   uint   `gorm:"index;not null" json:"task_id"`
+	Task     Task   `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	AuthorID uint   `gorm:"index;not null" json:"author_id"`
+	Author   User   `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;" json:"-"`
+	Body     string `gorm:"type:text;not null" json:"body"`
+}
+
+// TaskTemplate creates a new task in its project every time Recurrence fires.
+type TaskTemplate struct {
+	ID             uint          `gorm:"primaryKey" json:"id"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+	ProjectID      uint          `gorm:"index;not null" json:"project_id"`
+	CreatorID      uint          `gorm:"not null" json:"creator_id"`
+	Title          string        `gorm:"type:text;not null" json:"title"`
+	Description    string        `gorm:"type:text;not null;default:''" json:"description"`
+	AssigneeID     *uint         `json:"assignee_id"`
+	Priority       *TaskPriority `gorm:"type:text" json:"priority"`
+	EstimatePoints *int          `json:"estimate_points"`
+	LabelIDs       []uint        `gorm:"type:jsonb;serializer:json" json:"label_ids"`
+	// Created tasks are due this many days after the run, if set
+	DueInDays  *int       `json:"due_in_days"`
+	Recurrence string     `gorm:"type:text;not null" json:"recurrence"`
+	Timezone   string     `gorm:"type:text;not null;default:UTC" json:"timezone"`
+	Enabled    bool       `gorm:"not null" json:"enabled"`
+	NextRunAt  time.Time  `gorm:"not null" json:"next_run_at"`
+	LastRunAt  *time.Time `json:"last_run_at"`
+}
+
+// Label is a project-scoped, free-form replacement for Task.Tag.
+type Label struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	ProjectID uint      `gorm:"index;not null" json:"project_id"`
+	Name      string    `gorm:"type:text;not null" json:"name"`
+	Color     string    `gorm:"type:text;not null;default:#6b7280" json:"color"`
+}
+
+type TaskLabel struct {
+	TaskID  uint `gorm:"primaryKey"`
+	LabelID uint `gorm:"primaryKey;index"`
+}
+
+type TaskChecklistItem struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	TaskID    uint      `gorm:"index;not null" json:"task_id"`
+	Body      string    `gorm:"type:text;not null" json:"body"`
+	Done      bool      `gorm:"not null;default:false" json:"done"`
+	Position  float64   `gorm:"not null;default:1000" json:"position"`
+}
+
+type TaskCommentMention struct {
+	CommentID uint `gorm:"primaryKey"`
+	UserID    uint `gorm:"primaryKey;index"`
+}
+
+type GitHubEventStatus string
+
+const (
+	GitHubEventPending    GitHubEventStatus = "pending"
+	GitHubEventProcessing GitHubEventStatus = "processing"
+	GitHubEventDone       GitHubEventStatus = "done"
+	GitHubEventIgnored    GitHubEventStatus = "ignored"
+	GitHubEventFailed     GitHubEventStatus = "failed"
+)
+
+type GitHubEvent struct {
+	DeliveryID    string            `gorm:"column:delivery_id;primaryKey" json:"delivery_id"`
+	Event         string            `gorm:"not null" json:"event"`
+	ReceivedAt    time.Time         `gorm:"not null;default:now()" json:"received_at"`
+	Payload       *string           `gorm:"type:jsonb" json:"-"`
+	Status        GitHubEventStatus `gorm:"type:text;not null;default:pending" json:"status"`
+	Attempts      int               `gorm:"not null;default:0" json:"attempts"`
+	LastError     *string           `json:"last_error"`
+	NextAttemptAt time.Time         `gorm:"not null;default:now()" json:"next_attempt_at"`
+	ProcessedAt   *time.Time        `json:"processed_at"`
+	TasksUpdated  *int64            `json:"tasks_updated"`
+	// Tasks whose last open blocker was completed by this delivery
+	TasksUnblocked []uint `gorm:"type:jsonb;serializer:json" json:"tasks_unblocked"`
+}
+
+func (GitHubEvent) TableName() string { return "github_event_log" }
+
+type StatusCategory string
+
+const (
+	StatusCategoryTodo  StatusCategory = "todo"
+	StatusCategoryDoing StatusCategory = "doing"
+	StatusCategoryDone  StatusCategory = "done"
+)
+
+type ProjectStatus struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	ProjectID uint           `gorm:"uniqueIndex:uq_project_statuses_key;not null" json:"project_id"`
+	Key       string         `gorm:"uniqueIndex:uq_project_statuses_key;type:text;not null" json:"key"`
+	Label     string         `gorm:"type:text;not null" json:"label"`
+	Position  int            `gorm:"not null" json:"position"`
+	Category  StatusCategory `gorm:"type:text;not null" json:"category"`
+}
+
+// RepoWebhook is a webhook installed through the API, with the secret its
+// deliveries are signed with.
+type RepoWebhook struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	RepoFullName  string    `gorm:"column:repo_full_name;type:text;not null" json:"repo_full_name"`
+	Secret        string    `gorm:"type:text;not null" json:"-"`
+	HookID        int64     `gorm:"not null" json:"hook_id"`
+	InstalledByID *uint     `json:"installed_by_id"`
+}
+
+// GitHubAppInstallation is an installation of the GitHub App, when
+// Hydianflow runs as one.
+type GitHubAppInstallation struct {
+	ID           int64      `gorm:"primaryKey;autoIncrement:false" json:"id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	AccountLogin string     `gorm:"type:text;not null;default:''" json:"account_login"`
+	SuspendedAt  *time.Time `json:"suspended_at"`
+}
+
+// GitHubAppRepo is a repository a GitHub App installation has access to.
+type GitHubAppRepo struct {
+	RepoFullName   string `gorm:"column:repo_full_name;primaryKey" json:"repo_full_name"`
+	InstallationID int64  `gorm:"index;not null" json:"installation_id"`
 }
@@ -0,0 +1,285 @@
+package ghwebhook
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+// AdminRouter exposes github_event_log for debugging. Mount it behind
+// auth.RequireAdmin.
+func AdminRouter(db *database.DB) http.Handler {
+	h := &Handler{DB: db.DB}
+	r := chi.NewRouter()
+	r.Get("/", h.ListDeliveries)
+	r.Get("/{deliveryID}", h.GetDelivery)
+	r.Post("/{deliveryID}/replay", h.ReplayDelivery)
+	return r
+}
+
+type DeliveryResponse struct {
+	DeliveryID     string                     `json:"delivery_id"`
+	Event          string                     `json:"event"`
+	Repo           *string                    `json:"repo_full_name"`
+	Status         database.GitHubEventStatus `json:"status"`
+	Attempts       int                        `json:"attempts"`
+	LastError      *string                    `json:"last_error,omitempty"`
+	TasksUpdated   *int64                     `json:"tasks_updated,omitempty"`
+	TasksUnblocked []uint                     `json:"tasks_unblocked,omitempty"`
+	ReceivedAt     time.Time                  `json:"received_at"`
+	NextAttemptAt  *time.Time                 `json:"next_attempt_at,omitempty"`
+	ProcessedAt    *time.Time                 `json:"processed_at,omitempty"`
+}
+
+type DeliveryDetail struct {
+	DeliveryResponse
+	// What the handlers will match tasks against
+	Summary deliverySummary `json:"summary"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+type deliverySummary struct {
+	Action        string `json:"action,omitempty"`
+	Ref           string `json:"ref,omitempty"`
+	Branch        string `json:"branch,omitempty"`
+	DefaultBranch string `json:"default_branch,omitempty"`
+	HeadRef       string `json:"head_ref,omitempty"`
+	BaseRef       string `json:"base_ref,omitempty"`
+	Merged        bool   `json:"merged,omitempty"`
+	TaskRefs      []int  `json:"task_refs,omitempty"`
+}
+
+type deliveryRow struct {
+	database.GitHubEvent
+	Repo *string
+	// Only selected when loading a single delivery
+	PayloadText string
+}
+
+func (d deliveryRow) toResp() DeliveryResponse {
+	out := DeliveryResponse{
+		DeliveryID:     d.DeliveryID,
+		Event:          d.Event,
+		Repo:           d.Repo,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		LastError:      d.LastError,
+		TasksUpdated:   d.TasksUpdated,
+		TasksUnblocked: d.TasksUnblocked,
+		ReceivedAt:     d.ReceivedAt,
+		ProcessedAt:    d.ProcessedAt,
+	}
+	if d.Status == database.GitHubEventPending || d.Status == database.GitHubEventProcessing {
+		next := d.NextAttemptAt
+		out.NextAttemptAt = &next
+	}
+	return out
+}
+
+const deliveryColumns = "delivery_id, event, received_at, status, attempts, last_error, next_attempt_at, processed_at, tasks_updated, tasks_unblocked, " +
+	"payload->'repository'->>'full_name' AS repo"
+
+// GET /api/v1/admin/webhooks?event=&repo_full_name=&status=&since=&until=&cursor=
+func (h *Handler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	q := h.DB.Table("github_event_log").Select(deliveryColumns)
+
+	if ev := strings.TrimSpace(qs.Get("event")); ev != "" {
+		q = q.Where("event = ?", ev)
+	}
+	if repo := strings.TrimSpace(qs.Get("repo_full_name")); repo != "" {
+		q = q.Where("payload->'repository'->>'full_name' = ?", repo)
+	}
+	if st := strings.TrimSpace(qs.Get("status")); st != "" {
+		q = q.Where("status IN ?", strings.Split(st, ","))
+	}
+	for param, op := range map[string]string{"since": ">=", "until": "<"} {
+		v := strings.TrimSpace(qs.Get(param))
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			utils.Error(w, http.StatusBadRequest, "validation", "invalid "+param+" (want RFC3339)")
+			return
+		}
+		q = q.Where("received_at "+op+" ?", t)
+	}
+	if c := qs.Get("cursor"); c != "" {
+		at, id, err := decodeDeliveryCursor(c)
+		if err != nil {
+			utils.Error(w, http.StatusBadRequest, "validation", "invalid cursor")
+			return
+		}
+		q = q.Where("(received_at, delivery_id) < (?, ?)", at, id)
+	}
+
+	limit := 50
+	if n, err := strconv.Atoi(qs.Get("limit")); err == nil && n > 0 && n <= 200 {
+		limit = n
+	}
+
+	var rows []deliveryRow
+	if err := q.Order("received_at DESC, delivery_id DESC").Limit(limit).Scan(&rows).Error; err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_list", "could not list deliveries")
+		return
+	}
+
+	items := make([]DeliveryResponse, len(rows))
+	for i := range rows {
+		items[i] = rows[i].toResp()
+	}
+	next := ""
+	if len(rows) == limit {
+		last := rows[len(rows)-1]
+		next = encodeDeliveryCursor(last.ReceivedAt, last.DeliveryID)
+	}
+
+	type listResp struct {
+		Items      []DeliveryResponse `json:"items"`
+		NextCursor string             `json:"next_cursor"`
+	}
+	utils.JSON(w, http.StatusOK, listResp{Items: items, NextCursor: next})
+}
+
+// GET /api/v1/admin/webhooks/{deliveryID}
+func (h *Handler) GetDelivery(w http.ResponseWriter, r *http.Request) {
+	row, payload, ok := h.loadDelivery(w, r)
+	if !ok {
+		return
+	}
+	utils.JSON(w, http.StatusOK, DeliveryDetail{
+		DeliveryResponse: row.toResp(),
+		Summary:          summarize(row.Event, payload),
+		Payload:          json.RawMessage(payload),
+	})
+}
+
+// replayLease is how long a replay holds its claim on a delivery before the
+// worker may take it over.
+const replayLease = 2 * time.Minute
+
+// POST /api/v1/admin/webhooks/{deliveryID}/replay
+//
+// Runs the stored payload through the same handlers as the worker, inline,
+// and records the outcome on the delivery.
+func (h *Handler) ReplayDelivery(w http.ResponseWriter, r *http.Request) {
+	row, payload, ok := h.loadDelivery(w, r)
+	if !ok {
+		return
+	}
+
+	// Claim the row the way the worker does so it can't pick it up while the
+	// replay runs; the update waits on a worker's claim lock and then sees
+	// the row as processing
+	claim := h.DB.Exec(`
+		UPDATE github_event_log
+		SET status = 'processing',
+		    attempts = attempts + 1,
+		    next_attempt_at = now() + make_interval(secs => ?)
+		WHERE delivery_id = ? AND status <> 'processing'`,
+		replayLease.Seconds(), row.DeliveryID)
+	if claim.Error != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_update", "could not record replay")
+		return
+	}
+	if claim.RowsAffected == 0 {
+		utils.Error(w, http.StatusConflict, "in_progress", "delivery is being processed by the worker")
+		return
+	}
+
+	res, handled, perr := h.process(row.DeliveryID, row.Event, payload)
+	// maxAttempts 0: a failed manual replay is never retried automatically
+	if err := h.finish(row.DeliveryID, row.Attempts+1, 0, res, handled, perr); err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_update", "could not record replay result")
+		return
+	}
+
+	out := map[string]any{
+		"delivery_id": row.DeliveryID,
+		"event":       row.Event,
+		"handled":     handled,
+		"updated":     res.Updated,
+		"unblocked":   res.Unblocked,
+	}
+	if perr != nil {
+		out["error"] = perr.Error()
+	}
+	utils.JSON(w, http.StatusOK, out)
+}
+
+func (h *Handler) loadDelivery(w http.ResponseWriter, r *http.Request) (deliveryRow, []byte, bool) {
+	var row deliveryRow
+	err := h.DB.Table("github_event_log").
+		Select(deliveryColumns+", COALESCE(payload::text, 'null') AS payload_text").
+		Where("delivery_id = ?", chi.URLParam(r, "deliveryID")).
+		Take(&row).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.Error(w, http.StatusNotFound, "not_found", "delivery not found")
+			return deliveryRow{}, nil, false
+		}
+		utils.Error(w, http.StatusInternalServerError, "db_get", "could not load delivery")
+		return deliveryRow{}, nil, false
+	}
+	return row, []byte(row.PayloadText), true
+}
+
+func summarize(event string, payload []byte) deliverySummary {
+	var s deliverySummary
+	switch event {
+	case "push":
+		var p pushPayload
+		if json.Unmarshal(payload, &p) != nil {
+			return s
+		}
+		s.Ref = p.Ref
+		s.Branch = strings.TrimPrefix(p.Ref, "refs/heads/")
+		s.DefaultBranch = p.Repository.DefaultBranch
+		for _, c := range p.Commits {
+			for _, m := range taskRef.FindAllStringSubmatch(c.Message, -1) {
+				if id, err := strconv.Atoi(m[1]); err == nil {
+					s.TaskRefs = append(s.TaskRefs, id)
+				}
+			}
+		}
+	case "pull_request":
+		var p pullRequestPayload
+		if json.Unmarshal(payload, &p) != nil {
+			return s
+		}
+		s.Action = p.Action
+		s.DefaultBranch = p.Repository.DefaultBranch
+		s.HeadRef = p.PullRequest.Head.Ref
+		s.BaseRef = p.PullRequest.Base.Ref
+		s.Merged = p.PullRequest.Merged
+	}
+	return s
+}
+
+func encodeDeliveryCursor(at time.Time, id string) string {
+	raw := at.UTC().Format(time.RFC3339Nano) + "|" + id
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeDeliveryCursor(c string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(c)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	at, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return time.Time{}, "", errors.New("malformed cursor")
+	}
+	t, err := time.Parse(time.RFC3339Nano, at)
+	return t, id, err
+}
@@ -7,14 +7,15 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
-	"time"
 
+	"github.com/AJMerr/hydianflow/internal/database"
 	"github.com/AJMerr/hydianflow/internal/utils"
 	"gorm.io/gorm"
 )
 
 type Handler struct {
-	DB     *gorm.DB
+	DB *gorm.DB
+	// Shared secret for webhooks configured by hand
 	Secret []byte
 }
 
@@ -33,50 +34,44 @@ func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
 		utils.Error(w, http.StatusBadRequest, "bad_body", "could not read body")
 		return
 	}
-	if !verifySignature256(r.Header.Get("X-Hub-Signature-256"), body, h.Secret) {
+	valid, err := h.verifySignature256(r.Header.Get("X-Hub-Signature-256"), body)
+	if err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_get", "could not load webhook secret")
+		return
+	}
+	if !valid {
 		utils.Error(w, http.StatusUnauthorized, "bad_signature", "signature mismatch")
 		return
 	}
 
-	var insertedID string
+	// Log the delivery for the worker. A redelivery of a delivery that
+	// permanently failed is queued again instead of reported as a duplicate.
+	var queued []struct {
+		DeliveryID string
+		Requeued   bool
+	}
 	if err := h.DB.
 		Raw(`INSERT INTO github_event_log (delivery_id, event, payload)
 		     VALUES (?, ?, ?::jsonb)
-		     ON CONFLICT (delivery_id) DO NOTHING
-		     RETURNING delivery_id`, delivery, event, string(body)).
-		Scan(&insertedID).Error; err != nil {
+		     ON CONFLICT (delivery_id) DO UPDATE
+		       SET status = 'pending', attempts = 0, last_error = NULL,
+		           next_attempt_at = now(), processed_at = NULL
+		       WHERE github_event_log.status = 'failed'
+		     RETURNING delivery_id, (xmax <> 0) AS requeued`, delivery, event, string(body)).
+		Scan(&queued).Error; err != nil {
 		utils.Error(w, http.StatusInternalServerError, "db_event_log", "failed to log event")
 		return
 	}
-	if insertedID == "" {
+	if len(queued) == 0 {
 		utils.JSON(w, http.StatusOK, map[string]any{"duplicate": true})
 		return
 	}
 
-	switch event {
-	case "push":
-		updated, perr := h.handlePush(body)
-		if perr != nil {
-			utils.Error(w, http.StatusBadRequest, "push_parse", perr.Error())
-			return
-		}
-		utils.JSON(w, http.StatusOK, map[string]any{
-			"updated": updated,
-			"event":   "push",
-		})
-	case "pull_request":
-		updated, perr := h.handlePullRequest(body)
-		if perr != nil {
-			utils.Error(w, http.StatusBadRequest, "pr_parse", perr.Error())
-			return
-		}
-		utils.JSON(w, http.StatusOK, map[string]any{
-			"updated": updated,
-			"event":   "pull_request",
-		})
-	default:
-		utils.JSON(w, http.StatusOK, map[string]any{"ignored_event": event})
-	}
+	utils.JSON(w, http.StatusAccepted, map[string]any{
+		"queued":   true,
+		"requeued": queued[0].Requeued,
+		"event":    event,
+	})
 }
 
 type pushPayload struct {
@@ -90,22 +85,12 @@ type pushPayload struct {
 	} `json:"commits"`
 }
 
-type pullRequestPayload struct {
-	Action     string `json:"action"`
-	Repository struct {
-		FullName      string `json:"full_name"`
-		DefaultBranch string `json:"default_branch"`
-	} `json:"repository"`
-	PullRequest struct {
-		Merged bool `json:"merged"`
-		Base   struct {
-			Ref string `json:"ref"`
-		} `json:"base"`
-		Head struct {
-			Ref string `json:"ref"`
-		} `json:"head"`
-	} `json:"pull_request"`
-}
+// Status categories a webhook may move a task out of
+var (
+	todoCategories = []database.StatusCategory{database.StatusCategoryTodo}
+	openCategories = []database.StatusCategory{database.StatusCategoryTodo, database.StatusCategoryDoing}
+	doneCategories = []database.StatusCategory{database.StatusCategoryDone}
+)
 
 var taskRef = regexp.MustCompile(`(?i)(?:#|task:)\s*(\d+)`)
 
@@ -140,20 +125,18 @@ func uniqueLowerTrim(ss []string) []string {
 	return out
 }
 
-func (h *Handler) handlePush(body []byte) (int64, error) {
+func (h *Handler) handlePush(delivery string, body []byte) (outcome, error) {
+	var total outcome
 	var p pushPayload
 	if err := json.Unmarshal(body, &p); err != nil {
-		return 0, err
+		return total, permanentError{err}
 	}
 	branch := strings.TrimPrefix(p.Ref, "refs/heads/")
 	repo := strings.TrimSpace(p.Repository.FullName)
 	if repo == "" || branch == "" {
-		return 0, nil
+		return total, nil
 	}
 
-	now := time.Now().UTC()
-	var total int64
-
 	if p.Repository.DefaultBranch != "" && branch == p.Repository.DefaultBranch {
 		ids := make([]int64, 0, 4)
 		for _, c := range p.Commits {
@@ -166,17 +149,12 @@ func (h *Handler) handlePush(body []byte) (int64, error) {
 			}
 		}
 		if len(ids) > 0 {
-			res := h.DB.Table("tasks").
-				Where("id IN ? AND repo_full_name = ? AND status IN ('todo','in_progress')", ids, repo).
-				Updates(map[string]any{
-					"status":       "done",
-					"completed_at": gorm.Expr("COALESCE(completed_at, ?)", now),
-					"updated_at":   now,
-				})
-			if res.Error != nil {
-				return total, res.Error
+			n, err := h.transition(delivery, database.StatusCategoryDone, openCategories,
+				"id IN ? AND repo_full_name = ?", ids, repo)
+			total.add(n)
+			if err != nil {
+				return total, err
 			}
-			total += res.RowsAffected
 		}
 
 		mergedBranches := make([]string, 0, 4)
@@ -190,93 +168,29 @@ func (h *Handler) handlePush(body []byte) (int64, error) {
 			}
 			allPrefixes = uniqueLowerTrim(allPrefixes)
 			if len(allPrefixes) > 0 {
-				res := h.DB.Table("tasks").
-					Where(`
+				n, err := h.transition(delivery, database.StatusCategoryDone, openCategories, `
 						repo_full_name = ?
-						AND status IN ('todo','in_progress')
 						AND branch_hint <> ''
 						AND LOWER(TRIM(branch_hint)) IN (?)
-					`, repo, allPrefixes).
-					Updates(map[string]any{
-						"status":       "done",
-						"completed_at": gorm.Expr("COALESCE(completed_at, ?)", now),
-						"updated_at":   now,
-					})
-				if res.Error != nil {
-					return total, res.Error
+					`, repo, allPrefixes)
+				total.add(n)
+				if err != nil {
+					return total, err
 				}
-				total += res.RowsAffected
 			}
 		}
 
-		res := h.DB.Table("tasks").
-			Where(`
+		n, err := h.transition(delivery, database.StatusCategoryDone, openCategories, `
 				repo_full_name = ?
-				AND status IN ('todo','in_progress')
 				AND branch_hint <> ''
 				AND LOWER(TRIM(branch_hint)) = LOWER(TRIM(?))
-			`, repo, branch).
-			Updates(map[string]any{
-				"status":       "done",
-				"completed_at": gorm.Expr("COALESCE(completed_at, ?)", now),
-				"updated_at":   now,
-			})
-		if res.Error != nil {
-			return total, res.Error
-		}
-		total += res.RowsAffected
-
-		return total, nil
+			`, repo, branch)
+		total.add(n)
+		return total, err
 	}
 
 	prefixes := branchPrefix(branch)
-	res := h.DB.Table("tasks").
-		Where("repo_full_name = ? AND status = 'todo' AND branch_hint <> '' AND branch_hint IN (?)",
-			repo, prefixes).
-		Updates(map[string]any{
-			"status":     "in_progress",
-			"updated_at": now,
-		})
-	return res.RowsAffected, res.Error
-}
-
-func (h *Handler) handlePullRequest(body []byte) (int64, error) {
-	var p pullRequestPayload
-	if err := json.Unmarshal(body, &p); err != nil {
-		return 0, err
-	}
-	if p.Action != "closed" || !p.PullRequest.Merged {
-		return 0, nil
-	}
-	repo := strings.TrimSpace(p.Repository.FullName)
-	base := strings.TrimSpace(p.PullRequest.Base.Ref)
-	head := strings.TrimSpace(p.PullRequest.Head.Ref)
-	if repo == "" || base == "" || head == "" {
-		return 0, nil
-	}
-
-	// Only marks done when merged into repo's default branch (main/master)
-	if p.Repository.DefaultBranch != "" && base != p.Repository.DefaultBranch {
-		return 0, nil
-	}
-
-	now := time.Now().UTC()
-
-	// Move from in progress -> done for matching branch
-	res := h.DB.Table("tasks").
-		Where(`
-			repo_full_name = ?
-			AND status = 'in_progress'
-			AND branch_hint <> ''
-			AND (
-				LOWER(TRIM(branch_hint)) = LOWER(TRIM(?))
-				OR LOWER(TRIM(?)) LIKE LOWER(TRIM(branch_hint)) || '/%'
-			)
-		`, repo, head, head).
-		Updates(map[string]any{
-			"status":       "done",
-			"completed_at": gorm.Expr("COALESCE(completed_at, ?)", now),
-			"updated_at":   now,
-		})
-	return res.RowsAffected, res.Error
+	return h.transition(delivery, database.StatusCategoryDoing, todoCategories,
+		"repo_full_name = ? AND branch_hint <> '' AND branch_hint IN (?)",
+		repo, prefixes)
 }
@@ -0,0 +1,136 @@
+package ghwebhook
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/AJMerr/hydianflow/internal/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type appInstallation struct {
+	ID      int64 `json:"id"`
+	Account struct {
+		Login string `json:"login"`
+	} `json:"account"`
+}
+
+type appRepo struct {
+	FullName string `json:"full_name"`
+}
+
+type installationPayload struct {
+	Action       string          `json:"action"`
+	Installation appInstallation `json:"installation"`
+	Repositories []appRepo       `json:"repositories"`
+}
+
+type installationReposPayload struct {
+	Action              string          `json:"action"`
+	Installation        appInstallation `json:"installation"`
+	RepositoriesAdded   []appRepo       `json:"repositories_added"`
+	RepositoriesRemoved []appRepo       `json:"repositories_removed"`
+}
+
+// handleInstallation records GitHub App installations: created ones with
+// their repositories, suspended ones so their tokens aren't used, and
+// deleted ones along with their repositories.
+func (h *Handler) handleInstallation(body []byte) (outcome, error) {
+	var out outcome
+	var p installationPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return out, permanentError{err}
+	}
+	if p.Installation.ID == 0 {
+		return out, nil
+	}
+
+	return out, h.DB.Transaction(func(tx *gorm.DB) error {
+		switch p.Action {
+		case "deleted":
+			// Its repositories go with it
+			return tx.Delete(&database.GitHubAppInstallation{}, p.Installation.ID).Error
+		case "suspend":
+			now := time.Now().UTC()
+			return saveInstallation(tx, p.Installation, &now)
+		case "created":
+			if err := saveInstallation(tx, p.Installation, nil); err != nil {
+				return err
+			}
+			return addAppRepos(tx, p.Installation.ID, p.Repositories)
+		case "unsuspend", "new_permissions_accepted":
+			return saveInstallation(tx, p.Installation, nil)
+		}
+		return nil
+	})
+}
+
+// handleInstallationRepos keeps the repositories of an installation in step
+// as they are granted or revoked.
+func (h *Handler) handleInstallationRepos(body []byte) (outcome, error) {
+	var out outcome
+	var p installationReposPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return out, permanentError{err}
+	}
+	if p.Installation.ID == 0 {
+		return out, nil
+	}
+
+	return out, h.DB.Transaction(func(tx *gorm.DB) error {
+		// The installation may predate the app's webhook being set up
+		var existing int64
+		if err := tx.Model(&database.GitHubAppInstallation{}).
+			Where("id = ?", p.Installation.ID).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing == 0 {
+			if err := saveInstallation(tx, p.Installation, nil); err != nil {
+				return err
+			}
+		}
+
+		if err := addAppRepos(tx, p.Installation.ID, p.RepositoriesAdded); err != nil {
+			return err
+		}
+		if len(p.RepositoriesRemoved) == 0 {
+			return nil
+		}
+		names := make([]string, len(p.RepositoriesRemoved))
+		for i, r := range p.RepositoriesRemoved {
+			names[i] = r.FullName
+		}
+		return tx.Where("installation_id = ? AND repo_full_name IN ?", p.Installation.ID, names).
+			Delete(&database.GitHubAppRepo{}).Error
+	})
+}
+
+func saveInstallation(tx *gorm.DB, in appInstallation, suspendedAt *time.Time) error {
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"account_login", "suspended_at", "updated_at"}),
+	}).Create(&database.GitHubAppInstallation{
+		ID:           in.ID,
+		AccountLogin: in.Account.Login,
+		SuspendedAt:  suspendedAt,
+	}).Error
+}
+
+// addAppRepos assigns repos to the installation, taking them over from any
+// installation they were recorded under before.
+func addAppRepos(tx *gorm.DB, installationID int64, repos []appRepo) error {
+	rows := make([]database.GitHubAppRepo, 0, len(repos))
+	for _, r := range repos {
+		if r.FullName != "" {
+			rows = append(rows, database.GitHubAppRepo{RepoFullName: r.FullName, InstallationID: installationID})
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "repo_full_name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"installation_id"}),
+	}).Create(&rows).Error
+}
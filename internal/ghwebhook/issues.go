@@ -0,0 +1,114 @@
+package ghwebhook
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/history"
+	"github.com/AJMerr/hydianflow/internal/realtime"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type issuesPayload struct {
+	Action string `json:"action"`
+	Issue  struct {
+		Number int     `json:"number"`
+		Title  string  `json:"title"`
+		Body   *string `json:"body"`
+	} `json:"issue"`
+	Changes struct {
+		Title *struct {
+			From string `json:"from"`
+		} `json:"title"`
+		Body *struct {
+			From string `json:"from"`
+		} `json:"body"`
+	} `json:"changes"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// Tasks imported from an issue
+const issueTaskMatch = "repo_full_name = ? AND issue_number = ?"
+
+// handleIssues keeps tasks imported from an issue in step with it: closing
+// completes them, reopening moves completed ones back to todo, and edits to
+// the title or body are copied over.
+func (h *Handler) handleIssues(delivery string, body []byte) (outcome, error) {
+	var out outcome
+	var p issuesPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return out, permanentError{err}
+	}
+	repo := strings.TrimSpace(p.Repository.FullName)
+	number := p.Issue.Number
+	if repo == "" || number == 0 {
+		return out, nil
+	}
+
+	switch p.Action {
+	case "closed":
+		return h.transition(delivery, database.StatusCategoryDone, openCategories, issueTaskMatch, repo, number)
+	case "reopened":
+		return h.transition(delivery, database.StatusCategoryTodo, doneCategories, issueTaskMatch, repo, number)
+	case "edited":
+		if p.Changes.Title == nil && p.Changes.Body == nil {
+			return out, nil
+		}
+		n, err := h.editIssueTasks(delivery, repo, number, p)
+		out.Updated = n
+		return out, err
+	}
+	return out, nil
+}
+
+func (h *Handler) editIssueTasks(delivery, repo string, number int, p issuesPayload) (int64, error) {
+	var updated int64
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		var rows []database.Task
+		if err := tx.Where(issueTaskMatch, repo, number).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Find(&rows).Error; err != nil {
+			return err
+		}
+
+		actor := history.WebhookActor(delivery)
+		now := time.Now().UTC()
+		for _, t := range rows {
+			before := t
+			if p.Changes.Title != nil {
+				t.Title = p.Issue.Title
+			}
+			if p.Changes.Body != nil {
+				t.Description = ""
+				if p.Issue.Body != nil {
+					t.Description = *p.Issue.Body
+				}
+			}
+			changes := history.Diff(before, t)
+			if len(changes) == 0 {
+				continue
+			}
+			if err := tx.Model(&database.Task{}).Where("id = ?", t.ID).Updates(map[string]any{
+				"title":       t.Title,
+				"description": t.Description,
+				"updated_at":  now,
+			}).Error; err != nil {
+				return err
+			}
+			if err := history.Record(tx, t.ID, actor, changes...); err != nil {
+				return err
+			}
+			if err := realtime.PublishTask(tx, realtime.TaskUpdated, realtime.SourceWebhook, t); err != nil {
+				return err
+			}
+			updated++
+		}
+		return nil
+	})
+	return updated, err
+}
@@ -0,0 +1,96 @@
+package ghwebhook
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/AJMerr/hydianflow/internal/database"
+	"gorm.io/gorm"
+)
+
+// permanentError marks payload problems that retrying will not fix.
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+func isPermanent(err error) bool {
+	var pe permanentError
+	return errors.As(err, &pe)
+}
+
+// outcome is what processing a delivery changed.
+type outcome struct {
+	Updated int64
+	// Tasks whose last open blocker was completed
+	Unblocked []uint
+}
+
+func (o *outcome) add(n outcome) {
+	o.Updated += n.Updated
+	o.Unblocked = append(o.Unblocked, n.Unblocked...)
+}
+
+// process runs a logged delivery through the matching event handler.
+// handled is false for events Hydianflow does not act on.
+func (h *Handler) process(delivery, event string, body []byte) (out outcome, handled bool, err error) {
+	switch event {
+	case "push":
+		out, err = h.handlePush(delivery, body)
+		return out, true, err
+	case "pull_request":
+		out, err = h.handlePullRequest(delivery, body)
+		return out, true, err
+	case "issues":
+		out, err = h.handleIssues(delivery, body)
+		return out, true, err
+	case "installation":
+		out, err = h.handleInstallation(body)
+		return out, true, err
+	case "installation_repositories":
+		out, err = h.handleInstallationRepos(body)
+		return out, true, err
+	default:
+		return out, false, nil
+	}
+}
+
+// finish stores the outcome of processing a delivery. Transient errors are
+// retried with backoff until maxAttempts is reached.
+func (h *Handler) finish(delivery string, attempts, maxAttempts int, out outcome, handled bool, perr error) error {
+	now := time.Now().UTC()
+	updates := map[string]any{}
+
+	switch {
+	case perr == nil:
+		updates["status"] = database.GitHubEventDone
+		if !handled {
+			updates["status"] = database.GitHubEventIgnored
+		}
+		updates["processed_at"] = now
+		updates["last_error"] = nil
+		updates["tasks_updated"] = out.Updated
+		updates["tasks_unblocked"] = gorm.Expr("?::jsonb", unblockedJSON(out.Unblocked))
+	case isPermanent(perr) || attempts >= maxAttempts:
+		updates["status"] = database.GitHubEventFailed
+		updates["processed_at"] = now
+		updates["last_error"] = perr.Error()
+	default:
+		updates["status"] = database.GitHubEventPending
+		updates["next_attempt_at"] = now.Add(retryBackoff(attempts))
+		updates["last_error"] = perr.Error()
+	}
+
+	return h.DB.Model(&database.GitHubEvent{}).
+		Where("delivery_id = ?", delivery).
+		Updates(updates).Error
+}
+
+func unblockedJSON(ids []uint) string {
+	if len(ids) == 0 {
+		return "[]"
+	}
+	b, _ := json.Marshal(ids)
+	return string(b)
+}
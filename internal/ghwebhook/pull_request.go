@@ -0,0 +1,202 @@
+package ghwebhook
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/history"
+	"github.com/AJMerr/hydianflow/internal/realtime"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type pullRequestPayload struct {
+	Action     string `json:"action"`
+	Number     int    `json:"number"`
+	Repository struct {
+		FullName      string `json:"full_name"`
+		DefaultBranch string `json:"default_branch"`
+	} `json:"repository"`
+	PullRequest struct {
+		Number  int    `json:"number"`
+		HTMLURL string `json:"html_url"`
+		State   string `json:"state"`
+		Draft   bool   `json:"draft"`
+		Merged  bool   `json:"merged"`
+		Base    struct {
+			Ref string `json:"ref"`
+		} `json:"base"`
+		Head struct {
+			Ref string `json:"ref"`
+		} `json:"head"`
+	} `json:"pull_request"`
+}
+
+const (
+	prStateOpen   = "open"
+	prStateClosed = "closed"
+	prStateMerged = "merged"
+)
+
+// Tasks a pull request belongs to: already linked by number, or whose
+// branch_hint is the head branch (or a path prefix of it, compared literally
+// so _ and % in hints are not wildcards).
+const prTaskMatch = `
+	repo_full_name = ?
+	AND (
+		pr_number = ?
+		OR (
+			branch_hint <> ''
+			AND (
+				LOWER(TRIM(branch_hint)) = LOWER(TRIM(?))
+				OR starts_with(LOWER(TRIM(?)), LOWER(TRIM(branch_hint)) || '/')
+			)
+		)
+	)`
+
+func (h *Handler) handlePullRequest(delivery string, body []byte) (outcome, error) {
+	var out outcome
+	var p pullRequestPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return out, permanentError{err}
+	}
+	repo := strings.TrimSpace(p.Repository.FullName)
+	base := strings.TrimSpace(p.PullRequest.Base.Ref)
+	head := strings.TrimSpace(p.PullRequest.Head.Ref)
+	number := p.PullRequest.Number
+	if number == 0 {
+		number = p.Number
+	}
+	if repo == "" || base == "" || head == "" || number == 0 {
+		return out, nil
+	}
+
+	state := prStateOpen
+	switch p.Action {
+	case "opened", "reopened", "ready_for_review", "converted_to_draft":
+	case "closed":
+		state = prStateClosed
+		if p.PullRequest.Merged {
+			state = prStateMerged
+		}
+	default:
+		return out, nil
+	}
+
+	// A newly opened PR claims matching tasks even if they were linked to an
+	// older PR; later actions only touch tasks already linked to this one.
+	claim := p.Action == "opened" || p.Action == "reopened"
+	linked, err := h.linkPullRequest(delivery, repo, head, number, p.PullRequest.HTMLURL, state, p.PullRequest.Draft, claim)
+	out.Updated = linked
+	if err != nil {
+		return out, err
+	}
+
+	where := prTaskMatch + " AND (pr_number IS NULL OR pr_number = ?)"
+	switch {
+	case p.Action == "opened", p.Action == "reopened", p.Action == "ready_for_review":
+		// An open PR, draft or not, means work has started
+		moved, err := h.transition(delivery, database.StatusCategoryDoing, todoCategories, where,
+			repo, number, head, head, number)
+		out.add(moved)
+		return out, err
+	case state == prStateMerged:
+		// Only marks done when merged into repo's default branch (main/master)
+		if p.Repository.DefaultBranch != "" && base != p.Repository.DefaultBranch {
+			return out, nil
+		}
+		moved, err := h.transition(delivery, database.StatusCategoryDone, openCategories, where,
+			repo, number, head, head, number)
+		out.add(moved)
+		return out, err
+	}
+	return out, nil
+}
+
+type prTaskRow struct {
+	ID        uint
+	ProjectID *uint
+	Status    string
+	PRNumber  *int
+	PRState   *string
+}
+
+// linkPullRequest stores the PR number, URL, state and draft flag on every
+// matching task, recording history for number and state changes.
+func (h *Handler) linkPullRequest(delivery, repo, head string, number int, url, state string, draft, claim bool) (int64, error) {
+	now := time.Now().UTC()
+	var linked int64
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		q := tx.Table("tasks").
+			Select("id, project_id, status, pr_number, pr_state").
+			Where("deleted_at IS NULL").
+			Where(prTaskMatch, repo, number, head, head)
+		if !claim {
+			q = q.Where("pr_number IS NULL OR pr_number = ?", number)
+		}
+		var rows []prTaskRow
+		if err := q.Clauses(clause.Locking{Strength: "UPDATE"}).Scan(&rows).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(rows))
+		for i, r := range rows {
+			ids[i] = r.ID
+		}
+		updates := map[string]any{
+			"pr_number":  number,
+			"pr_state":   state,
+			"pr_draft":   draft,
+			"updated_at": now,
+		}
+		if url != "" {
+			updates["pr_url"] = url
+		}
+		if err := tx.Table("tasks").Where("id IN ?", ids).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		actor := history.WebhookActor(delivery)
+		numStr := strconv.Itoa(number)
+		for _, r := range rows {
+			changes := make([]history.Change, 0, 2)
+			if r.PRNumber == nil || *r.PRNumber != number {
+				var old *string
+				if r.PRNumber != nil {
+					old = history.Str(strconv.Itoa(*r.PRNumber))
+				}
+				changes = append(changes, history.Change{Field: "pr_number", OldValue: old, NewValue: history.Str(numStr)})
+			}
+			if r.PRState == nil || *r.PRState != state {
+				changes = append(changes, history.Change{Field: "pr_state", OldValue: r.PRState, NewValue: history.Str(state)})
+			}
+			if len(changes) == 0 {
+				continue
+			}
+			linked++
+			if err := history.Record(tx, r.ID, actor, changes...); err != nil {
+				return err
+			}
+			if r.ProjectID != nil {
+				if err := realtime.Publish(tx, realtime.Event{
+					Type:      realtime.TaskUpdated,
+					TaskID:    r.ID,
+					ProjectID: *r.ProjectID,
+					Status:    r.Status,
+					Source:    realtime.SourceWebhook,
+				}); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	return linked, err
+}
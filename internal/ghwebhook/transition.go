@@ -0,0 +1,135 @@
+package ghwebhook
+
+import (
+	"slices"
+	"time"
+
+	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/history"
+	"github.com/AJMerr/hydianflow/internal/realtime"
+	"github.com/AJMerr/hydianflow/internal/workflow"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type taskState struct {
+	ID           uint
+	Status       string
+	ProjectID    *uint
+	ParentTaskID *uint
+}
+
+type statusMove struct {
+	from, to string
+}
+
+// transition moves every task matching where whose status falls in one of
+// the from categories to the first status of the to category in its
+// project's workflow. Each move is recorded in task history, attributed to
+// the webhook delivery, and project boards are notified once the
+// transaction commits. Tasks left without open blockers by a move to done
+// are reported as unblocked.
+func (h *Handler) transition(delivery string, to database.StatusCategory, from []database.StatusCategory, where string, args ...any) (outcome, error) {
+	now := time.Now().UTC()
+	var out outcome
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the rows first so the recorded old status is accurate
+		var rows []taskState
+		if err := tx.Table("tasks").
+			Select("id, status, project_id, parent_task_id").
+			Where("deleted_at IS NULL").
+			Where(where, args...).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Scan(&rows).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		workflows := workflow.NewCache(tx)
+		groups := make(map[statusMove][]uint, 2)
+		var parents, movedIDs []uint
+		for _, r := range rows {
+			wf, err := workflows.Get(r.ProjectID)
+			if err != nil {
+				return err
+			}
+			cat, ok := wf.Category(r.Status)
+			if !ok || !slices.Contains(from, cat) {
+				continue
+			}
+			target := wf.First(to)
+			if target == "" || target == r.Status {
+				continue
+			}
+			mv := statusMove{from: r.Status, to: target}
+			groups[mv] = append(groups[mv], r.ID)
+			movedIDs = append(movedIDs, r.ID)
+			if r.ParentTaskID != nil {
+				parents = append(parents, *r.ParentTaskID)
+			}
+
+			if r.ProjectID != nil {
+				if err := realtime.Publish(tx, realtime.Event{
+					Type:      realtime.TaskUpdated,
+					TaskID:    r.ID,
+					ProjectID: *r.ProjectID,
+					Status:    target,
+					Source:    realtime.SourceWebhook,
+				}); err != nil {
+					return err
+				}
+			}
+		}
+
+		actor := history.WebhookActor(delivery)
+		for mv, ids := range groups {
+			updates := map[string]any{
+				"status":     mv.to,
+				"updated_at": now,
+			}
+			switch to {
+			case database.StatusCategoryDoing:
+				updates["started_at"] = gorm.Expr("COALESCE(started_at, ?)", now)
+			case database.StatusCategoryDone:
+				updates["completed_at"] = gorm.Expr("COALESCE(completed_at, ?)", now)
+			}
+			// Same rule as workflow.Stamp: only done tasks have a completion time
+			if to != database.StatusCategoryDone {
+				updates["completed_at"] = nil
+			}
+			res := tx.Table("tasks").Where("id IN ?", ids).Updates(updates)
+			if res.Error != nil {
+				return res.Error
+			}
+			out.Updated += res.RowsAffected
+
+			if err := history.RecordEach(tx, ids, actor, history.Change{
+				Field:    "status",
+				OldValue: history.Str(mv.from),
+				NewValue: history.Str(mv.to),
+			}); err != nil {
+				return err
+			}
+		}
+		if to != database.StatusCategoryDone {
+			return nil
+		}
+		completed, err := workflow.CompleteParents(tx, actor, realtime.SourceWebhook, parents...)
+		if err != nil {
+			return err
+		}
+		unblocked, err := workflow.ReleaseDependents(tx, actor, realtime.SourceWebhook, append(movedIDs, completed...))
+		if err != nil {
+			return err
+		}
+		out.Unblocked = unblocked
+		return nil
+	})
+	if err != nil {
+		return outcome{}, err
+	}
+	return out, nil
+}
@@ -5,10 +5,58 @@ import (
 	"crypto/sha256"
 	"crypto/subtle"
 	"encoding/hex"
+	"encoding/json"
 	"strings"
+
+	"github.com/AJMerr/hydianflow/internal/database"
 )
 
-func verifySignature256(sigHeader string, body []byte, secret []byte) bool {
+// verifySignature256 checks a delivery against the secret generated for its
+// repository when Hydianflow installed the webhook. Repositories without one,
+// and deliveries that name no repository, use the shared secret of webhooks
+// configured by hand.
+func (h *Handler) verifySignature256(sigHeader string, body []byte) (bool, error) {
+	var repoSecret []byte
+	// A payload that doesn't parse can still only match the shared secret
+	if name := payloadRepo(body); name != "" {
+		var hooks []database.RepoWebhook
+		if err := h.DB.Select("secret").
+			Where("LOWER(repo_full_name) = LOWER(?)", name).
+			Limit(1).Find(&hooks).Error; err != nil {
+			return false, err
+		}
+		if len(hooks) == 1 {
+			repoSecret = []byte(hooks[0].Secret)
+		}
+	}
+	return matchSignature256(sigHeader, body, repoSecret, h.Secret), nil
+}
+
+// payloadRepo returns the repository a delivery is about, or "" if the
+// payload doesn't name one.
+func payloadRepo(body []byte) string {
+	var p struct {
+		Repository struct {
+			FullName string `json:"full_name"`
+		} `json:"repository"`
+	}
+	if json.Unmarshal(body, &p) != nil {
+		return ""
+	}
+	return p.Repository.FullName
+}
+
+// matchSignature256 accepts a signature made with the repository's secret,
+// or with the shared one when the repository has none. The shared secret
+// must not vouch for repositories that have their own.
+func matchSignature256(sigHeader string, body, repoSecret, shared []byte) bool {
+	if len(repoSecret) > 0 {
+		return validSignature256(sigHeader, body, repoSecret)
+	}
+	return validSignature256(sigHeader, body, shared)
+}
+
+func validSignature256(sigHeader string, body []byte, secret []byte) bool {
 	if len(secret) == 0 || sigHeader == "" {
 		return false
 	}
@@ -0,0 +1,66 @@
+package ghwebhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestMatchSignature256(t *testing.T) {
+	body := []byte(`{"repository":{"full_name":"acme/api"}}`)
+	const repo, shared = "repo-secret", "shared-secret"
+
+	tests := []struct {
+		name       string
+		sig        string
+		repoSecret string
+		shared     string
+		want       bool
+	}{
+		{"repo secret", sign(repo, body), repo, shared, true},
+		{"shared secret", sign(shared, body), repo, shared, false},
+		{"shared secret, repo has none", sign(shared, body), "", shared, true},
+		{"repo secret, no shared", sign(repo, body), repo, "", true},
+		{"upper-case hex and prefix", "SHA256=" + strings.ToUpper(strings.TrimPrefix(sign(repo, body), "sha256=")), repo, shared, true},
+		{"another repo's secret", sign("other", body), repo, shared, false},
+		{"no secrets configured", sign("", body), "", "", false},
+		{"empty header", "", repo, shared, false},
+		{"wrong algorithm", strings.Replace(sign(repo, body), "sha256=", "sha1=", 1), repo, shared, false},
+		{"no prefix", strings.TrimPrefix(sign(repo, body), "sha256="), repo, shared, false},
+		{"truncated", sign(repo, body)[:40], repo, shared, false},
+		{"tampered body", sign(repo, []byte(`{"repository":{"full_name":"acme/web"}}`)), repo, shared, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchSignature256(tt.sig, body, []byte(tt.repoSecret), []byte(tt.shared)); got != tt.want {
+				t.Errorf("matchSignature256 = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPayloadRepo(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`{"repository":{"full_name":"acme/api"},"action":"opened"}`, "acme/api"},
+		{`{"zen":"Keep it logically awesome."}`, ""},
+		{`{"repository":null}`, ""},
+		{`not json`, ""},
+		{``, ""},
+	}
+	for _, tt := range tests {
+		if got := payloadRepo([]byte(tt.body)); got != tt.want {
+			t.Errorf("payloadRepo(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package ghwebhook
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/AJMerr/hydianflow/internal/database"
+	"gorm.io/gorm"
+)
+
+// Worker drains github_event_log in the background. Rows are claimed with
+// FOR UPDATE SKIP LOCKED so several API replicas can run workers safely.
+type Worker struct {
+	H           *Handler
+	Interval    time.Duration
+	BatchSize   int
+	MaxAttempts int
+	// A claimed row is retried after Lease if its worker never reports back
+	Lease time.Duration
+}
+
+func NewWorker(db *database.DB) *Worker {
+	return &Worker{
+		H:           &Handler{DB: db.DB},
+		Interval:    time.Second,
+		BatchSize:   10,
+		MaxAttempts: 8,
+		Lease:       2 * time.Minute,
+	}
+}
+
+// Run processes deliveries until ctx is cancelled.
+func (wk *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(wk.Interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := wk.RunOnce()
+		if err != nil {
+			log.Printf("webhook worker: %v", err)
+		}
+		// A full batch likely means more work is waiting
+		if err == nil && n == wk.BatchSize {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+type claimedEvent struct {
+	DeliveryID string
+	Event      string
+	Payload    string
+	Attempts   int
+}
+
+// RunOnce claims and processes one batch, returning how many were claimed.
+func (wk *Worker) RunOnce() (int, error) {
+	rows, err := wk.claim()
+	if err != nil {
+		return 0, err
+	}
+	for _, c := range rows {
+		out, handled, perr := wk.H.process(c.DeliveryID, c.Event, []byte(c.Payload))
+		if err := wk.H.finish(c.DeliveryID, c.Attempts, wk.MaxAttempts, out, handled, perr); err != nil {
+			log.Printf("webhook worker: finish %s: %v", c.DeliveryID, err)
+		}
+	}
+	return len(rows), nil
+}
+
+func (wk *Worker) claim() ([]claimedEvent, error) {
+	var rows []claimedEvent
+	err := wk.H.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Raw(`
+			SELECT delivery_id, event, COALESCE(payload::text, '') AS payload, attempts
+			FROM github_event_log
+			WHERE status IN ('pending','processing') AND next_attempt_at <= now()
+			ORDER BY received_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED`, wk.BatchSize).
+			Scan(&rows).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		ids := make([]string, len(rows))
+		for i := range rows {
+			ids[i] = rows[i].DeliveryID
+			rows[i].Attempts++
+		}
+		return tx.Exec(`
+			UPDATE github_event_log
+			SET status = 'processing',
+			    attempts = attempts + 1,
+			    next_attempt_at = now() + make_interval(secs => ?)
+			WHERE delivery_id IN ?`, wk.Lease.Seconds(), ids).Error
+	})
+	return rows, err
+}
+
+// retryBackoff doubles from 10s per attempt, capped at an hour.
+func retryBackoff(attempt int) time.Duration {
+	d := 10 * time.Second
+	for i := 1; i < attempt && d < time.Hour; i++ {
+		d *= 2
+	}
+	if d > time.Hour {
+		d = time.Hour
+	}
+	return d
+}
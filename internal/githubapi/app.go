@@ -0,0 +1,240 @@
+package githubapi
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// GitHub rejects app JWTs that live longer than ten minutes
+	appJWTLifetime = 9 * time.Minute
+	// Covers clock drift between us and GitHub
+	appJWTBackdate = time.Minute
+	// Installation tokens are renewed this long before they expire
+	tokenRenewMargin = 2 * time.Minute
+)
+
+// App mints installation access tokens for a GitHub App. Tokens are cached
+// per installation until shortly before they expire.
+type App struct {
+	ID  int64
+	Key *rsa.PrivateKey
+	// API root, e.g. "https://api.github.com/"; a stand-in server in tests
+	BaseURL    string
+	HTTPClient *http.Client
+
+	mu    sync.Mutex
+	slots map[int64]*tokenSlot
+	now   func() time.Time
+}
+
+// tokenSlot caches one installation's token. sem is held while the token is
+// checked or renewed, so concurrent callers wait for one exchange instead of
+// each starting their own, without holding up other installations.
+type tokenSlot struct {
+	sem   chan struct{}
+	token installationToken
+}
+
+type installationToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// NewApp returns an App authenticating as appID with the PEM-encoded
+// private key. An empty baseURL means api.github.com.
+func NewApp(appID int64, keyPEM []byte, baseURL string) (*App, error) {
+	key, err := parsePrivateKey(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	if baseURL == "" {
+		baseURL = "https://api.github.com/"
+	}
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	return &App{
+		ID:         appID,
+		Key:        key,
+		BaseURL:    baseURL,
+		HTTPClient: http.DefaultClient,
+		slots:      map[int64]*tokenSlot{},
+		now:        time.Now,
+	}, nil
+}
+
+// NewAppFromEnv configures an App from GITHUB_APP_ID and either
+// GITHUB_APP_PRIVATE_KEY or GITHUB_APP_PRIVATE_KEY_PATH. It returns nil
+// when no app is configured.
+func NewAppFromEnv() (*App, error) {
+	rawID := strings.TrimSpace(os.Getenv("GITHUB_APP_ID"))
+	keyPEM := []byte(os.Getenv("GITHUB_APP_PRIVATE_KEY"))
+	if path := strings.TrimSpace(os.Getenv("GITHUB_APP_PRIVATE_KEY_PATH")); path != "" && len(keyPEM) == 0 {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read GITHUB_APP_PRIVATE_KEY_PATH: %w", err)
+		}
+		keyPEM = b
+	}
+	if rawID == "" && len(keyPEM) == 0 {
+		return nil, nil
+	}
+	id, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil || id <= 0 {
+		return nil, errors.New("GITHUB_APP_ID must be the numeric app id")
+	}
+	return NewApp(id, keyPEM, strings.TrimSpace(os.Getenv("GITHUB_API_URL")))
+}
+
+func parsePrivateKey(keyPEM []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("github app private key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse github app private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("github app private key is not an RSA key")
+	}
+	return key, nil
+}
+
+// JWT signs the short-lived RS256 token that authenticates as the app
+// itself.
+func (a *App) JWT() (string, error) {
+	now := a.now()
+	enc := base64.RawURLEncoding
+	header := enc.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	claims, err := json.Marshal(map[string]int64{
+		"iat": now.Add(-appJWTBackdate).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": a.ID,
+	})
+	if err != nil {
+		return "", err
+	}
+	signing := header + "." + enc.EncodeToString(claims)
+	sum := sha256.Sum256([]byte(signing))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, a.Key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", err
+	}
+	return signing + "." + enc.EncodeToString(sig), nil
+}
+
+// InstallationToken returns an access token for the installation, reusing
+// the cached one until it is about to expire.
+func (a *App) InstallationToken(ctx context.Context, installationID int64) (string, error) {
+	slot := a.slot(installationID)
+	select {
+	case slot.sem <- struct{}{}:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	defer func() { <-slot.sem }()
+
+	if slot.token.Token != "" && a.now().Add(tokenRenewMargin).Before(slot.token.ExpiresAt) {
+		return slot.token.Token, nil
+	}
+	t, err := a.exchange(ctx, installationID)
+	if err != nil {
+		return "", err
+	}
+	slot.token = t
+	return t.Token, nil
+}
+
+func (a *App) slot(installationID int64) *tokenSlot {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	s, ok := a.slots[installationID]
+	if !ok {
+		s = &tokenSlot{sem: make(chan struct{}, 1)}
+		a.slots[installationID] = s
+	}
+	return s
+}
+
+// exchange trades the app JWT for a new installation access token.
+func (a *App) exchange(ctx context.Context, installationID int64) (installationToken, error) {
+	var t installationToken
+	jwt, err := a.JWT()
+	if err != nil {
+		return t, err
+	}
+	endpoint := a.BaseURL + "app/installations/" + strconv.FormatInt(installationID, 10) + "/access_tokens"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, nil)
+	if err != nil {
+		return t, err
+	}
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := a.HTTPClient.Do(req)
+	if err != nil {
+		return t, err
+	}
+	defer resp.Body.Close()
+	// Lets callers map failures the same way as other API errors
+	if err := github.CheckResponse(resp); err != nil {
+		return t, err
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&t); err != nil {
+		return t, fmt.Errorf("decode installation token: %w", err)
+	}
+	if t.Token == "" {
+		return t, errors.New("github returned an empty installation token")
+	}
+	return t, nil
+}
+
+// Client returns an API client acting as the installation.
+func (a *App) Client(ctx context.Context, installationID int64) (*github.Client, error) {
+	token, err := a.InstallationToken(ctx, installationID)
+	if err != nil {
+		return nil, err
+	}
+	src := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
+	return newClient(oauth2.NewClient(ctx, src), a.BaseURL)
+}
+
+// newClient is github.NewClient pointed at baseURL when one is set.
+func newClient(hc *http.Client, baseURL string) (*github.Client, error) {
+	cli := github.NewClient(hc)
+	if baseURL == "" {
+		return cli, nil
+	}
+	u, err := url.Parse(strings.TrimRight(baseURL, "/") + "/")
+	if err != nil {
+		return nil, err
+	}
+	cli.BaseURL = u
+	return cli, nil
+}
@@ -0,0 +1,370 @@
+package githubapi
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+)
+
+const testAppID = 4242
+
+var (
+	testKeyOnce sync.Once
+	testKey     *rsa.PrivateKey
+)
+
+func rsaKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	testKeyOnce.Do(func() {
+		k, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			panic(err)
+		}
+		testKey = k
+	})
+	return testKey
+}
+
+func pkcs1PEM(k *rsa.PrivateKey) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(k)})
+}
+
+// fakeGitHub stands in for the installation token endpoint and one
+// repository endpoint authenticated with the issued tokens.
+type fakeGitHub struct {
+	t   *testing.T
+	key *rsa.PublicKey
+	now func() time.Time
+
+	mu        sync.Mutex
+	exchanges map[int64]int
+	// Per installation, closed to let a held exchange finish
+	hold map[int64]chan struct{}
+}
+
+func newFakeGitHub(t *testing.T, key *rsa.PublicKey, now func() time.Time) (*fakeGitHub, *httptest.Server) {
+	f := &fakeGitHub{t: t, key: key, now: now, exchanges: map[int64]int{}, hold: map[int64]chan struct{}{}}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var id int64
+	if _, err := fmt.Sscanf(r.URL.Path, "/app/installations/%d/access_tokens", &id); err == nil {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method", http.StatusMethodNotAllowed)
+			return
+		}
+		claims, err := verifyJWT(f.key, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+		if err != nil || claims["iss"] != testAppID {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message":"A JSON web token could not be decoded"}`))
+			return
+		}
+		f.mu.Lock()
+		hold := f.hold[id]
+		f.mu.Unlock()
+		if hold != nil {
+			<-hold
+		}
+		if id == 404 {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+			return
+		}
+		f.mu.Lock()
+		f.exchanges[id]++
+		n := f.exchanges[id]
+		f.mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"token":      fmt.Sprintf("ghs_%d_%d", id, n),
+			"expires_at": f.now().Add(time.Hour).UTC().Format(time.RFC3339),
+		})
+		return
+	}
+	if r.URL.Path == "/repos/octo/hello/branches" {
+		_ = json.NewEncoder(w).Encode([]map[string]string{{"name": r.Header.Get("Authorization")}})
+		return
+	}
+	f.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	http.NotFound(w, r)
+}
+
+func (f *fakeGitHub) count(id int64) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.exchanges[id]
+}
+
+// verifyJWT checks an RS256 JWT's signature and returns its claims.
+func verifyJWT(pub *rsa.PublicKey, jwt string) (map[string]int64, error) {
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("want three parts")
+	}
+	enc := base64.RawURLEncoding
+	var header map[string]string
+	raw, err := enc.DecodeString(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return nil, err
+	}
+	if header["alg"] != "RS256" || header["typ"] != "JWT" {
+		return nil, fmt.Errorf("header %v", header)
+	}
+	sig, err := enc.DecodeString(parts[2])
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, sum[:], sig); err != nil {
+		return nil, err
+	}
+	var claims map[string]int64
+	raw, err = enc.DecodeString(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	return claims, json.Unmarshal(raw, &claims)
+}
+
+// testApp returns an app on a fake clock backed by a fake GitHub.
+func testApp(t *testing.T) (*App, *fakeGitHub, *time.Time) {
+	t.Helper()
+	key := rsaKey(t)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	f, srv := newFakeGitHub(t, &key.PublicKey, clock)
+	app, err := NewApp(testAppID, pkcs1PEM(key), srv.URL)
+	if err != nil {
+		t.Fatalf("NewApp: %v", err)
+	}
+	app.now = clock
+	return app, f, &now
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	key := rsaKey(t)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ec, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecDER, err := x509.MarshalPKCS8PrivateKey(ec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		pem     []byte
+		wantErr bool
+	}{
+		{"pkcs1", pkcs1PEM(key), false},
+		{"pkcs8", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}), false},
+		{"not pem", []byte("not a key"), true},
+		{"garbage der", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("nope")}), true},
+		{"not rsa", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: ecDER}), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePrivateKey(tt.pem)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(key) {
+				t.Fatal("parsed a different key")
+			}
+		})
+	}
+}
+
+func TestAppJWT(t *testing.T) {
+	app, _, now := testApp(t)
+
+	jwt, err := app.JWT()
+	if err != nil {
+		t.Fatalf("JWT: %v", err)
+	}
+	claims, err := verifyJWT(&app.Key.PublicKey, jwt)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	want := map[string]int64{
+		"iss": testAppID,
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+	}
+	for k, v := range want {
+		if claims[k] != v {
+			t.Errorf("claim %s = %d, want %d", k, claims[k], v)
+		}
+	}
+	if len(claims) != len(want) {
+		t.Errorf("claims = %v, want only %v", claims, want)
+	}
+	// GitHub refuses tokens valid for more than ten minutes
+	if claims["exp"]-claims["iat"] > 600 {
+		t.Errorf("lifetime %ds exceeds 10 minutes", claims["exp"]-claims["iat"])
+	}
+}
+
+func TestInstallationTokenCachesUntilNearExpiry(t *testing.T) {
+	app, f, now := testApp(t)
+	ctx := context.Background()
+	issued := *now
+
+	steps := []struct {
+		name      string
+		at        time.Time
+		wantToken string
+		wantCalls int
+	}{
+		{"first call exchanges", issued, "ghs_7_1", 1},
+		{"reused while fresh", issued.Add(30 * time.Minute), "ghs_7_1", 1},
+		{"reused just outside the renew margin", issued.Add(time.Hour - tokenRenewMargin - time.Second), "ghs_7_1", 1},
+		{"renewed inside the margin", issued.Add(time.Hour - tokenRenewMargin), "ghs_7_2", 2},
+		{"renewed token reused", issued.Add(time.Hour), "ghs_7_2", 2},
+		{"renewed after expiry", issued.Add(3 * time.Hour), "ghs_7_3", 3},
+	}
+	for _, s := range steps {
+		*now = s.at
+		got, err := app.InstallationToken(ctx, 7)
+		if err != nil {
+			t.Fatalf("%s: %v", s.name, err)
+		}
+		if got != s.wantToken {
+			t.Errorf("%s: token = %q, want %q", s.name, got, s.wantToken)
+		}
+		if c := f.count(7); c != s.wantCalls {
+			t.Errorf("%s: exchanges = %d, want %d", s.name, c, s.wantCalls)
+		}
+	}
+
+	// Installations are cached separately
+	if got, err := app.InstallationToken(ctx, 8); err != nil || got != "ghs_8_1" {
+		t.Errorf("installation 8: token = %q, err = %v", got, err)
+	}
+}
+
+func TestInstallationTokenError(t *testing.T) {
+	app, _, _ := testApp(t)
+
+	_, err := app.InstallationToken(context.Background(), 404)
+	var gerr *github.ErrorResponse
+	if !errors.As(err, &gerr) || gerr.Response.StatusCode != http.StatusNotFound {
+		t.Fatalf("err = %v, want a 404 *github.ErrorResponse", err)
+	}
+	if tok := app.slot(404).token; tok.Token != "" {
+		t.Errorf("failed exchange cached %q", tok.Token)
+	}
+}
+
+func TestInstallationTokenBadKey(t *testing.T) {
+	_, f, _ := testApp(t)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+	app, err := NewApp(testAppID, pkcs1PEM(other), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = app.InstallationToken(context.Background(), 7)
+	var gerr *github.ErrorResponse
+	if !errors.As(err, &gerr) || gerr.Response.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("err = %v, want a 401 *github.ErrorResponse", err)
+	}
+}
+
+func TestInstallationTokenLocksPerInstallation(t *testing.T) {
+	app, f, _ := testApp(t)
+	release := make(chan struct{})
+	f.mu.Lock()
+	f.hold[1] = release
+	f.mu.Unlock()
+
+	slow := make(chan error, 1)
+	go func() {
+		_, err := app.InstallationToken(context.Background(), 1)
+		slow <- err
+	}()
+	// Wait for the slow exchange to take installation 1's slot
+	for deadline := time.Now().Add(5 * time.Second); len(app.slot(1).sem) == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("exchange for installation 1 never started")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Another installation isn't held up by the slow exchange
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if got, err := app.InstallationToken(ctx, 2); err != nil || got != "ghs_2_1" {
+		t.Fatalf("installation 2: token = %q, err = %v", got, err)
+	}
+
+	// A second caller for the held installation waits, and gives up with
+	// its context
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer waitCancel()
+	if _, err := app.InstallationToken(waitCtx, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("waiting caller: err = %v, want deadline exceeded", err)
+	}
+
+	close(release)
+	if err := <-slow; err != nil {
+		t.Fatalf("installation 1: %v", err)
+	}
+	if got, err := app.InstallationToken(context.Background(), 1); err != nil || got != "ghs_1_1" {
+		t.Fatalf("installation 1 after release: token = %q, err = %v", got, err)
+	}
+	if c := f.count(1); c != 1 {
+		t.Errorf("installation 1 exchanges = %d, want 1", c)
+	}
+}
+
+func TestAppClient(t *testing.T) {
+	app, _, _ := testApp(t)
+	cli, err := app.Client(context.Background(), 9)
+	if err != nil {
+		t.Fatalf("Client: %v", err)
+	}
+
+	branches, _, err := cli.Repositories.ListBranches(context.Background(), "octo", "hello", nil)
+	if err != nil {
+		t.Fatalf("ListBranches: %v", err)
+	}
+	// The stand-in echoes the Authorization header as the branch name
+	if len(branches) != 1 || branches[0].GetName() != "Bearer ghs_9_1" {
+		t.Fatalf("branches = %v, want one authorized with the installation token", branches)
+	}
+}
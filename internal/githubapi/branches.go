@@ -8,7 +8,7 @@ import (
 )
 
 func (s *Service) ListBranches(ctx context.Context, userID uint, ownerRepo string, page, perPage int) ([]*github.Branch, *github.Response, error) {
-	cli, err := s.clientForUser(ctx, userID)
+	cli, _, err := s.clientForRepo(ctx, userID, ownerRepo, "pull")
 	if err != nil {
 		return nil, nil, err
 	}
@@ -31,3 +31,28 @@ func splitOwnerRepo(full string) (owner, repo string) {
 	}
 	return "", full
 }
+
+// CreateBranch creates name in ownerRepo pointing at the head of base, or of
+// the repository's default branch when base is empty. The user needs push
+// access, or ErrRepoAccess is returned, and the repo scope.
+func (s *Service) CreateBranch(ctx context.Context, userID uint, ownerRepo, name, base string) (*github.Reference, error) {
+	cli, r, err := s.clientForRepo(ctx, userID, ownerRepo, "push")
+	if err != nil {
+		return nil, err
+	}
+	owner, repo := splitOwnerRepo(ownerRepo)
+
+	if base == "" {
+		base = r.GetDefaultBranch()
+	}
+	from, _, err := cli.Git.GetRef(ctx, owner, repo, "refs/heads/"+base)
+	if err != nil {
+		return nil, err
+	}
+
+	ref, _, err := cli.Git.CreateRef(ctx, owner, repo, &github.Reference{
+		Ref:    github.Ptr("refs/heads/" + name),
+		Object: &github.GitObject{SHA: from.Object.SHA},
+	})
+	return ref, err
+}
@@ -3,25 +3,121 @@ package githubapi
 import (
 	"context"
 	"errors"
+	"net/http"
+	"strings"
 
 	"github.com/AJMerr/hydianflow/internal/database"
 	"github.com/google/go-github/v74/github"
 	"golang.org/x/oauth2"
 )
 
+var (
+	ErrNoToken = errors.New("missing github token for user")
+	// ErrScope means the user signed in before a scope the call needs was
+	// requested and must sign in again
+	ErrScope = errors.New("github token lacks a required scope")
+	// ErrRepoAccess means the user's GitHub account can't see a repository
+	// or lacks the permission a call needs on it
+	ErrRepoAccess = errors.New("github account cannot access the repository")
+)
+
 type Service struct {
 	DB *database.DB
+	// App, when set, acts on repositories its installations cover on behalf
+	// of users who have access to them
+	App *App
+	// API root for user clients; empty means api.github.com
+	BaseURL string
 }
 
 func (s *Service) clientForUser(ctx context.Context, userID uint) (*github.Client, error) {
+	return s.clientWithScope(ctx, userID, "")
+}
+
+// clientWithScope is clientForUser for calls that need a scope beyond the
+// read-only ones, e.g. "repo" to write to repositories.
+func (s *Service) clientWithScope(ctx context.Context, userID uint, scope string) (*github.Client, error) {
 	var u database.User
-	if err := s.DB.DB.Select("github_access_token").First(&u, userID).Error; err != nil {
+	if err := s.DB.DB.Select("github_access_token", "github_token_scope").First(&u, userID).Error; err != nil {
 		return nil, err
 	}
 	if u.GitHubAccessToken == nil || *u.GitHubAccessToken == "" {
-		return nil, errors.New("missing github token for user")
+		return nil, ErrNoToken
+	}
+	if scope != "" && (u.GitHubTokenScope == nil || !hasScope(*u.GitHubTokenScope, scope)) {
+		return nil, ErrScope
 	}
 	src := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: *u.GitHubAccessToken})
 	httpClient := oauth2.NewClient(ctx, src)
-	return github.NewClient(httpClient), nil
+	return newClient(httpClient, s.BaseURL)
+}
+
+// clientForRepo returns a client for a call that needs perm ("pull",
+// "triage" or "push") on ownerRepo. It acts as the GitHub App installation
+// covering the repository when there is one, but only once the user's own
+// token shows they hold perm there, so the app never reaches further than
+// the user could. Anything beyond pull needs the repo scope. The repository
+// is returned when it had to be loaded for the check.
+func (s *Service) clientForRepo(ctx context.Context, userID uint, ownerRepo, perm string) (*github.Client, *github.Repository, error) {
+	scope := "repo"
+	if perm == "pull" {
+		scope = ""
+	}
+	user, err := s.clientWithScope(ctx, userID, scope)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var ids []int64
+	if s.App != nil {
+		if err := s.DB.DB.Model(&database.GitHubAppRepo{}).
+			Joins("JOIN github_app_installations i ON i.id = github_app_repos.installation_id").
+			Where("LOWER(github_app_repos.repo_full_name) = LOWER(?) AND i.suspended_at IS NULL", ownerRepo).
+			Limit(1).Pluck("github_app_repos.installation_id", &ids).Error; err != nil {
+			return nil, nil, err
+		}
+	}
+	// GitHub enforces reads made with the user's own token
+	if len(ids) == 0 && perm == "pull" {
+		return user, nil, nil
+	}
+
+	r, err := repoAccess(ctx, user, ownerRepo, perm)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(ids) == 0 {
+		return user, r, nil
+	}
+	cli, err := s.App.Client(ctx, ids[0])
+	return cli, r, err
+}
+
+// hasScope checks GitHub's comma-separated granted scope list.
+func hasScope(granted, scope string) bool {
+	for _, s := range strings.FieldsFunc(granted, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// repoAccess loads ownerRepo as cli's user sees it and checks they hold
+// perm ("pull", "triage", "push", ...) on it. GitHub answers 404 for private
+// repositories the user can't see.
+func repoAccess(ctx context.Context, cli *github.Client, ownerRepo, perm string) (*github.Repository, error) {
+	owner, repo := splitOwnerRepo(ownerRepo)
+	r, _, err := cli.Repositories.Get(ctx, owner, repo)
+	var gerr *github.ErrorResponse
+	if errors.As(err, &gerr) && gerr.Response != nil && gerr.Response.StatusCode == http.StatusNotFound {
+		return nil, ErrRepoAccess
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !r.GetPermissions()[perm] {
+		return nil, ErrRepoAccess
+	}
+	return r, nil
 }
@@ -0,0 +1,44 @@
+package githubapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// Events Hydianflow's webhook handler acts on
+var hookEvents = []string{"push", "pull_request", "issues"}
+
+// InstallRepoHook points a webhook on ownerRepo at url, signed with secret.
+// hookID is the webhook installed earlier, if any; it is updated in place
+// and recreated if it was deleted on GitHub. It needs the repo scope and
+// admin rights on the repository.
+func (s *Service) InstallRepoHook(ctx context.Context, userID uint, ownerRepo, url, secret string, hookID int64) (*github.Hook, error) {
+	cli, err := s.clientWithScope(ctx, userID, "repo")
+	if err != nil {
+		return nil, err
+	}
+	owner, repo := splitOwnerRepo(ownerRepo)
+	hook := &github.Hook{
+		Events: hookEvents,
+		Active: github.Ptr(true),
+		Config: &github.HookConfig{
+			URL:         github.Ptr(url),
+			ContentType: github.Ptr("json"),
+			Secret:      github.Ptr(secret),
+			InsecureSSL: github.Ptr("0"),
+		},
+	}
+
+	if hookID != 0 {
+		out, _, err := cli.Repositories.EditHook(ctx, owner, repo, hookID, hook)
+		var gerr *github.ErrorResponse
+		if !errors.As(err, &gerr) || gerr.Response == nil || gerr.Response.StatusCode != http.StatusNotFound {
+			return out, err
+		}
+	}
+	out, _, err := cli.Repositories.CreateHook(ctx, owner, repo, hook)
+	return out, err
+}
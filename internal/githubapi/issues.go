@@ -0,0 +1,58 @@
+package githubapi
+
+import (
+	"context"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// ListIssues lists a repository's issues. GitHub returns pull requests from
+// the same endpoint; those are filtered out, so a page may hold fewer than
+// perPage items even when more follow.
+func (s *Service) ListIssues(ctx context.Context, userID uint, ownerRepo, state string, page, perPage int) ([]*github.Issue, *github.Response, error) {
+	cli, _, err := s.clientForRepo(ctx, userID, ownerRepo, "pull")
+	if err != nil {
+		return nil, nil, err
+	}
+	if perPage <= 0 || perPage > 100 {
+		perPage = 50
+	}
+	if page <= 0 {
+		page = 1
+	}
+	if state == "" {
+		state = "open"
+	}
+
+	owner, repo := splitOwnerRepo(ownerRepo)
+	opts := &github.IssueListByRepoOptions{
+		State:       state,
+		Sort:        "created",
+		Direction:   "asc",
+		ListOptions: github.ListOptions{Page: page, PerPage: perPage},
+	}
+	issues, resp, err := cli.Issues.ListByRepo(ctx, owner, repo, opts)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	out := issues[:0]
+	for _, is := range issues {
+		if is != nil && !is.IsPullRequest() {
+			out = append(out, is)
+		}
+	}
+	return out, resp, nil
+}
+
+// SetIssueState closes or reopens an issue. state is "open" or "closed". The
+// user needs triage access and the repo scope.
+func (s *Service) SetIssueState(ctx context.Context, userID uint, ownerRepo string, number int, state string) error {
+	cli, _, err := s.clientForRepo(ctx, userID, ownerRepo, "triage")
+	if err != nil {
+		return err
+	}
+	owner, repo := splitOwnerRepo(ownerRepo)
+	_, _, err = cli.Issues.Edit(ctx, owner, repo, number, &github.IssueRequest{State: &state})
+	return err
+}
@@ -0,0 +1,30 @@
+package githubapi
+
+import (
+	"context"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// CreatePullRequest opens a pull request from head into base, or into the
+// repository's default branch when base is empty. The user needs push
+// access, or ErrRepoAccess is returned, and the repo scope.
+func (s *Service) CreatePullRequest(ctx context.Context, userID uint, ownerRepo, head, base, title, body string, draft bool) (*github.PullRequest, error) {
+	cli, r, err := s.clientForRepo(ctx, userID, ownerRepo, "push")
+	if err != nil {
+		return nil, err
+	}
+	owner, repo := splitOwnerRepo(ownerRepo)
+
+	if base == "" {
+		base = r.GetDefaultBranch()
+	}
+	pr, _, err := cli.PullRequests.Create(ctx, owner, repo, &github.NewPullRequest{
+		Title: &title,
+		Head:  &head,
+		Base:  &base,
+		Body:  &body,
+		Draft: &draft,
+	})
+	return pr, err
+}
@@ -0,0 +1,67 @@
+package githubhttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/AJMerr/hydianflow/internal/githubapi"
+	"github.com/AJMerr/hydianflow/internal/utils"
+	"github.com/google/go-github/v74/github"
+)
+
+// WriteError maps a failed GitHub call to a specific error code so the UI
+// can tell the user what to fix. GitHub's 422s are validation failures whose
+// meaning depends on the call, so unprocessable, when set, picks their code.
+func WriteError(w http.ResponseWriter, err error, unprocessable func(msg string) (status int, code string)) {
+	var (
+		rate  *github.RateLimitError
+		abuse *github.AbuseRateLimitError
+		ghErr *github.ErrorResponse
+	)
+	switch {
+	case errors.Is(err, githubapi.ErrNoToken):
+		utils.Error(w, http.StatusConflict, "github_not_linked", "no GitHub account is linked")
+	case errors.Is(err, githubapi.ErrRepoAccess):
+		utils.Error(w, http.StatusForbidden, "github_repo_forbidden", "your GitHub account can't access this repository or lacks permission for this")
+	case errors.Is(err, githubapi.ErrScope):
+		utils.Error(w, http.StatusForbidden, "github_reauth_required", "sign in with GitHub again to grant repository access")
+	case errors.As(err, &rate), errors.As(err, &abuse):
+		utils.Error(w, http.StatusTooManyRequests, "github_rate_limited", "GitHub rate limit exceeded, try again later")
+	case errors.Is(err, context.DeadlineExceeded):
+		utils.Error(w, http.StatusGatewayTimeout, "github_timeout", "GitHub did not respond in time")
+	case errors.As(err, &ghErr) && ghErr.Response != nil:
+		msg := githubMessage(ghErr)
+		switch ghErr.Response.StatusCode {
+		case http.StatusUnauthorized:
+			utils.Error(w, http.StatusForbidden, "github_reauth_required", "GitHub token is no longer valid, sign in again")
+		case http.StatusForbidden:
+			utils.Error(w, http.StatusForbidden, "github_forbidden", msg)
+		case http.StatusNotFound:
+			utils.Error(w, http.StatusNotFound, "github_not_found", "repository or branch not found, or not accessible")
+		case http.StatusUnprocessableEntity:
+			status, code := http.StatusUnprocessableEntity, "github_validation"
+			if unprocessable != nil {
+				status, code = unprocessable(msg)
+			}
+			utils.Error(w, status, code, msg)
+		default:
+			utils.Error(w, http.StatusBadGateway, "github_error", msg)
+		}
+	default:
+		utils.Error(w, http.StatusBadGateway, "github_error", err.Error())
+	}
+}
+
+// githubMessage joins GitHub's top-level message with its field errors,
+// which is where the useful detail usually is.
+func githubMessage(e *github.ErrorResponse) string {
+	parts := []string{e.Message}
+	for _, fe := range e.Errors {
+		if fe.Message != "" {
+			parts = append(parts, fe.Message)
+		}
+	}
+	return strings.Join(parts, ": ")
+}
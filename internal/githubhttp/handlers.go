@@ -11,14 +11,19 @@ import (
 	"github.com/go-chi/chi/v5"
 )
 
-type Handler struct{ Svc *githubapi.Service }
+type Handler struct {
+	Svc *githubapi.Service
+	// Where installed webhooks deliver to
+	HookURL string
+}
 
-func Router(svc *githubapi.Service) http.Handler {
+func Router(svc *githubapi.Service, hookURL string) http.Handler {
 	r := chi.NewRouter()
-	h := &Handler{Svc: svc}
+	h := &Handler{Svc: svc, HookURL: hookURL}
 
 	r.Get("/repos", h.listRepos)
 	r.Get("/branches", h.listBranches)
+	r.Post("/webhooks", h.installWebhook)
 	return r
 }
 
@@ -37,7 +42,7 @@ func (h *Handler) listRepos(w http.ResponseWriter, r *http.Request) {
 
 	repos, _, err := h.Svc.ListUserRepos(r.Context(), uid, q, page, per)
 	if err != nil {
-		utils.Error(w, http.StatusBadGateway, "github_error", err.Error())
+		WriteError(w, err, nil)
 		return
 	}
 	// Minimal payload for UI
@@ -82,7 +87,7 @@ func (h *Handler) listBranches(w http.ResponseWriter, r *http.Request) {
 
 	branches, _, err := h.Svc.ListBranches(r.Context(), uid, repo, page, per)
 	if err != nil {
-		utils.Error(w, http.StatusBadGateway, "github_error", err.Error())
+		WriteError(w, err, nil)
 		return
 	}
 	type Branch struct{ Name string }
@@ -0,0 +1,127 @@
+package githubhttp
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/AJMerr/hydianflow/internal/auth"
+	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/githubapi"
+	"github.com/AJMerr/hydianflow/internal/utils"
+	"github.com/google/go-github/v74/github"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type installWebhookRequest struct {
+	RepoFullName string `json:"repo_full_name"`
+}
+
+type RepoWebhookResponse struct {
+	RepoFullName string `json:"repo_full_name"`
+	HookID       int64  `json:"hook_id"`
+	URL          string `json:"url"`
+	Created      bool   `json:"created"`
+}
+
+// POST /api/v1/github/webhooks
+//
+// Installs Hydianflow's webhook on a repository with the caller's token,
+// which needs admin rights on it. Each repository gets its own secret;
+// installing again updates the existing webhook and keeps the secret.
+func (h *Handler) installWebhook(w http.ResponseWriter, r *http.Request) {
+	uid, ok := auth.UserIDFromCtx(r.Context())
+	if !ok || uid == 0 {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "login required")
+		return
+	}
+	if h.HookURL == "" {
+		utils.Error(w, http.StatusServiceUnavailable, "webhook_unconfigured", "webhook URL is not configured")
+		return
+	}
+
+	var req installWebhookRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		utils.Error(w, http.StatusBadRequest, "bad_json", "invalid JSON body")
+		return
+	}
+	repo := strings.TrimSpace(req.RepoFullName)
+	if owner, name, ok := strings.Cut(repo, "/"); !ok || owner == "" || name == "" {
+		utils.Error(w, http.StatusBadRequest, "validation", "repo_full_name must be owner/repo")
+		return
+	}
+
+	db := h.Svc.DB.DB
+	var existing []database.RepoWebhook
+	if err := db.Where("LOWER(repo_full_name) = LOWER(?)", repo).Limit(1).Find(&existing).Error; err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_get", "could not load webhook")
+		return
+	}
+	row := database.RepoWebhook{RepoFullName: repo, Secret: webhookSecret()}
+	if len(existing) == 1 {
+		row = existing[0]
+	}
+
+	hook, err := h.Svc.InstallRepoHook(r.Context(), uid, repo, h.HookURL, row.Secret, row.HookID)
+	if err != nil {
+		writeInstallError(w, err)
+		return
+	}
+	created := row.HookID != hook.GetID()
+	row.HookID = hook.GetID()
+	row.InstalledByID = &uid
+
+	if row.ID != 0 {
+		err = db.Model(&row).Updates(map[string]any{
+			"hook_id":         row.HookID,
+			"installed_by_id": uid,
+		}).Error
+	} else {
+		res := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&row)
+		if err = res.Error; err == nil && res.RowsAffected == 0 {
+			utils.Error(w, http.StatusConflict, "webhook_install_conflict", "webhook was installed concurrently; try again")
+			return
+		}
+	}
+	if err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_update", "webhook installed but could not be saved")
+		return
+	}
+
+	utils.JSON(w, http.StatusOK, RepoWebhookResponse{
+		RepoFullName: row.RepoFullName,
+		HookID:       row.HookID,
+		URL:          h.HookURL,
+		Created:      created,
+	})
+}
+
+func writeInstallError(w http.ResponseWriter, err error) {
+	var gerr *github.ErrorResponse
+	switch {
+	case errors.Is(err, githubapi.ErrNoToken):
+		utils.Error(w, http.StatusConflict, "github_not_linked", "no GitHub account is linked")
+	case errors.Is(err, githubapi.ErrScope):
+		utils.Error(w, http.StatusForbidden, "github_reauth_required", "sign in with GitHub again to grant repository access")
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "user not found")
+	case errors.As(err, &gerr) && gerr.Response != nil &&
+		(gerr.Response.StatusCode == http.StatusNotFound || gerr.Response.StatusCode == http.StatusForbidden):
+		// GitHub hides repositories you can't administer behind a 404
+		utils.Error(w, http.StatusForbidden, "github_forbidden", "repository not found or you are not an admin of it")
+	default:
+		utils.Error(w, http.StatusBadGateway, "github_error", err.Error())
+	}
+}
+
+func webhookSecret() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,159 @@
+package history
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/AJMerr/hydianflow/internal/database"
+	"gorm.io/gorm"
+)
+
+// Fields recorded for task lifecycle events
+const (
+	FieldCreated = "created"
+	FieldDeleted = "deleted"
+	// FieldBlocked flips to false once a task's last open blocker is done
+	FieldBlocked = "blocked"
+	// FieldBlockedBy holds the blocker id of an added or removed dependency
+	FieldBlockedBy = "blocked_by"
+	// FieldLabels holds the comma-joined label names
+	FieldLabels = "labels"
+)
+
+// Actor identifies who changed a task: a signed-in user or a webhook delivery.
+type Actor struct {
+	UserID     *uint
+	DeliveryID *string
+}
+
+func UserActor(id uint) Actor {
+	return Actor{UserID: &id}
+}
+
+func WebhookActor(delivery string) Actor {
+	return Actor{DeliveryID: &delivery}
+}
+
+// Change is a single field transition on a task.
+type Change struct {
+	Field    string
+	OldValue *string
+	NewValue *string
+}
+
+// Diff lists the user-visible fields that differ between two versions of a
+// task. Position is left out since every drag would otherwise be recorded.
+func Diff(before, after database.Task) []Change {
+	out := make([]Change, 0, 4)
+	add := func(field string, a, b *string) {
+		if eq(a, b) {
+			return
+		}
+		out = append(out, Change{Field: field, OldValue: a, NewValue: b})
+	}
+	add("title", str(before.Title), str(after.Title))
+	add("description", str(before.Description), str(after.Description))
+	add("status", str(string(before.Status)), str(string(after.Status)))
+	add("tag", before.Tag, after.Tag)
+	add("assignee_id", uintStr(before.AssigneeID), uintStr(after.AssigneeID))
+	add("repo_full_name", before.RepoName, after.RepoName)
+	add("branch_hint", before.BranchHint, after.BranchHint)
+	add("project_id", uintStr(before.ProjectID), uintStr(after.ProjectID))
+	add("parent_task_id", uintStr(before.ParentTaskID), uintStr(after.ParentTaskID))
+	add("pr_number", intStr(before.PRNumber), intStr(after.PRNumber))
+	add("pr_state", before.PRState, after.PRState)
+	add("due_at", timeStr(before.DueAt), timeStr(after.DueAt))
+	add("priority", (*string)(before.Priority), (*string)(after.Priority))
+	add("estimate_points", intStr(before.EstimatePoints), intStr(after.EstimatePoints))
+	return out
+}
+
+// Record writes one event row per change.
+func Record(tx *gorm.DB, taskID uint, actor Actor, changes ...Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	rows := make([]database.TaskEvent, len(changes))
+	for i, c := range changes {
+		rows[i] = database.TaskEvent{
+			CreatedAt:       now,
+			TaskID:          taskID,
+			ActorUserID:     actor.UserID,
+			ActorDeliveryID: actor.DeliveryID,
+			Field:           c.Field,
+			OldValue:        c.OldValue,
+			NewValue:        c.NewValue,
+		}
+	}
+	if err := tx.Create(&rows).Error; err != nil {
+		return fmt.Errorf("record task events: %w", err)
+	}
+	return nil
+}
+
+// RecordEach writes the same change for many tasks, e.g. after a bulk update.
+func RecordEach(tx *gorm.DB, taskIDs []uint, actor Actor, c Change) error {
+	if len(taskIDs) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	rows := make([]database.TaskEvent, len(taskIDs))
+	for i, id := range taskIDs {
+		rows[i] = database.TaskEvent{
+			CreatedAt:       now,
+			TaskID:          id,
+			ActorUserID:     actor.UserID,
+			ActorDeliveryID: actor.DeliveryID,
+			Field:           c.Field,
+			OldValue:        c.OldValue,
+			NewValue:        c.NewValue,
+		}
+	}
+	if err := tx.Create(&rows).Error; err != nil {
+		return fmt.Errorf("record task events: %w", err)
+	}
+	return nil
+}
+
+// Str is a convenience for building Change values.
+func Str(s string) *string { return &s }
+
+func str(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func uintStr(v *uint) *string {
+	if v == nil {
+		return nil
+	}
+	s := strconv.FormatUint(uint64(*v), 10)
+	return &s
+}
+
+func intStr(v *int) *string {
+	if v == nil {
+		return nil
+	}
+	s := strconv.Itoa(*v)
+	return &s
+}
+
+func timeStr(v *time.Time) *string {
+	if v == nil {
+		return nil
+	}
+	s := v.UTC().Format(time.RFC3339)
+	return &s
+}
+
+func eq(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
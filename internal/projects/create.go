@@ -6,10 +6,10 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/AJMerr/hydianflow/internal/access"
 	"github.com/AJMerr/hydianflow/internal/auth"
 	"github.com/AJMerr/hydianflow/internal/database"
 	"github.com/AJMerr/hydianflow/internal/utils"
-	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
@@ -41,12 +41,8 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if req.ParentID != nil && *req.ParentID != 0 {
-		var parent database.Project
-		if err := h.DB.
-			Select("id").
-			Where("id = ? AND owner_id = ?", req.ParentID, uid).
-			First(&parent).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
+		if _, _, err := access.Require(h.DB, *req.ParentID, uid, database.ProjectRoleAdmin); err != nil {
+			if errors.Is(err, access.ErrNotFound) || errors.Is(err, access.ErrForbidden) {
 				utils.Error(w, http.StatusBadRequest, "validation", "parent project is not found")
 				return
 			}
@@ -66,7 +62,7 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 		Create(&database.ProjectMember{
 			ProjectID: p.ID,
 			UserID:    p.OwnerID,
-			Role:      "owner",
+			Role:      database.ProjectRoleOwner,
 		}).Error
 
 	utils.JSON(w, http.StatusCreated, toResp(p, false))
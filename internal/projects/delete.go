@@ -5,9 +5,8 @@ import (
 
 	"github.com/AJMerr/hydianflow/internal/auth"
 	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/history"
 	"github.com/AJMerr/hydianflow/internal/utils"
-	"github.com/go-chi/chi/v5"
-	"gorm.io/gorm"
 )
 
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
@@ -16,26 +15,38 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 		utils.Error(w, http.StatusUnauthorized, "unauthorized", "auth is required")
 		return
 	}
-	idStr := chi.URLParam(r, "id")
 
-	var p database.Project
-	if err := h.DB.Where("id = ? AND owner_id = ?", idStr, uid).First(&p).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			utils.Error(w, http.StatusNotFound, "not_found", "project not found")
-			return
-		}
-		utils.Error(w, http.StatusInternalServerError, "db", "load project failed")
+	p, _, ok := h.loadProject(w, r, uid, database.ProjectRoleOwner)
+	if !ok {
 		return
 	}
 
 	tx := h.DB.Begin()
 	defer func() { _ = tx.Rollback() }()
 
+	var taskIDs []uint
+	if err := tx.Model(&database.Task{}).Where("project_id = ?", p.ID).Pluck("id", &taskIDs).Error; err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db", "failed to load tasks")
+		return
+	}
+
 	if err := tx.Where("project_id = ?", p.ID).Delete(&database.Task{}).Error; err != nil {
 		utils.Error(w, http.StatusInternalServerError, "db", "failed to delete tasks")
 		return
 	}
 
+	if err := history.RecordEach(tx, taskIDs, history.UserActor(uid), history.Change{Field: history.FieldDeleted}); err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db", "failed to record task history")
+		return
+	}
+
+	// The soft delete doesn't cascade; stop the project's recurring tasks
+	if err := tx.Model(&database.TaskTemplate{}).Where("project_id = ?", p.ID).
+		Update("enabled", false).Error; err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db", "failed to disable templates")
+		return
+	}
+
 	if err := tx.Delete(&p).Error; err != nil {
 		utils.Error(w, http.StatusInternalServerError, "db", "failed to delete project")
 		return
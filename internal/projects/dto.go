@@ -1,6 +1,10 @@
 package projects
 
-import "time"
+import (
+	"time"
+
+	"github.com/AJMerr/hydianflow/internal/database"
+)
 
 type ProjectCreateRequest struct {
 	Name        string  `json:"name"`
@@ -18,4 +22,10 @@ type ProjectResponse struct {
 
 	ParentID    *uint `json:"parent_id"`
 	HasChildren bool  `json:"has_children"`
+
+	BlockedPolicy  database.BlockedPolicy `json:"blocked_policy"`
+	SyncIssueState bool                   `json:"sync_issue_state"`
+
+	// Only set with ?include=stats
+	Stats *ProjectStats `json:"stats,omitempty"`
 }
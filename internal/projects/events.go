@@ -0,0 +1,81 @@
+package projects
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/AJMerr/hydianflow/internal/access"
+	"github.com/AJMerr/hydianflow/internal/auth"
+	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/utils"
+)
+
+const sseHeartbeat = 25 * time.Second
+
+// GET /api/v1/projects/{id}/events (Server-Sent Events)
+func (h *Handler) Events(w http.ResponseWriter, r *http.Request) {
+	uid, ok := auth.UserIDFromCtx(r.Context())
+	if !ok || uid == 0 {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "auth required")
+		return
+	}
+
+	p, _, ok := h.loadProject(w, r, uid, database.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+	if h.Hub == nil {
+		utils.Error(w, http.StatusServiceUnavailable, "unavailable", "live updates are not enabled")
+		return
+	}
+
+	// Streams outlive the server's WriteTimeout, so lift the deadline here
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		utils.Error(w, http.StatusInternalServerError, "stream", "streaming not supported")
+		return
+	}
+
+	events, unsubscribe := h.Hub.Subscribe(p.ID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 3000\n\n")
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(sseHeartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			b, err := json.Marshal(ev)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, b)
+		case <-ticker.C:
+			// Drop the stream if the user has since lost access
+			if _, err := access.RoleFor(h.DB, p.ID, uid); err != nil {
+				return
+			}
+			fmt.Fprint(w, ": ping\n\n")
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
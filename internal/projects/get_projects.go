@@ -1,17 +1,15 @@
 package projects
 
 import (
-	"errors"
 	"net/http"
 
+	"github.com/AJMerr/hydianflow/internal/access"
 	"github.com/AJMerr/hydianflow/internal/auth"
 	"github.com/AJMerr/hydianflow/internal/database"
 	"github.com/AJMerr/hydianflow/internal/utils"
-	"github.com/go-chi/chi/v5"
-	"gorm.io/gorm"
 )
 
-// GET All
+// GET /api/v1/projects?include=stats
 func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	uid, ok := auth.UserIDFromCtx(r.Context())
 	if !ok || uid == 0 {
@@ -20,7 +18,7 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var rows []database.Project
-	if err := h.DB.Where("owner_id = ?", uid).
+	if err := h.DB.Where("id IN (?)", access.ProjectIDs(h.DB, uid)).
 		Order("created_at DESC").
 		Find(&rows).Error; err != nil {
 		utils.Error(w, http.StatusInternalServerError, "db_list", "could not list projects")
@@ -31,10 +29,24 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	for i := range rows {
 		out[i] = toResp(rows[i], false)
 	}
+	if wantsInclude(r, "stats") {
+		ids := make([]uint, len(rows))
+		for i := range rows {
+			ids[i] = rows[i].ID
+		}
+		stats, err := loadStats(h.DB, uid, ids)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "db_stats", "could not load project stats")
+			return
+		}
+		for i := range out {
+			out[i].Stats = stats[out[i].ID]
+		}
+	}
 	utils.JSON(w, http.StatusOK, out)
 }
 
-// GET /api/v1/projects/{id}
+// GET /api/v1/projects/{id}?include=stats
 func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
 	uid, ok := auth.UserIDFromCtx(r.Context())
 	if !ok || uid == 0 {
@@ -42,14 +54,8 @@ func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	idStr := chi.URLParam(r, "id")
-	var p database.Project
-	if err := h.DB.Where("id = ? AND owner_id = ?", idStr, uid).First(&p).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			utils.Error(w, http.StatusNotFound, "not_found", "project not found")
-			return
-		}
-		utils.Error(w, http.StatusInternalServerError, "db_get", "could not load project")
+	p, _, ok := h.loadProject(w, r, uid, database.ProjectRoleViewer)
+	if !ok {
 		return
 	}
 
@@ -61,5 +67,14 @@ func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	utils.JSON(w, http.StatusOK, toResp(p, childCount > 0))
+	out := toResp(p, childCount > 0)
+	if wantsInclude(r, "stats") {
+		stats, err := loadStats(h.DB, uid, []uint{p.ID})
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "db_stats", "could not load project stats")
+			return
+		}
+		out.Stats = stats[p.ID]
+	}
+	utils.JSON(w, http.StatusOK, out)
 }
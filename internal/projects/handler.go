@@ -1,7 +1,36 @@
 package projects
 
-import "gorm.io/gorm"
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/AJMerr/hydianflow/internal/access"
+	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/githubapi"
+	"github.com/AJMerr/hydianflow/internal/realtime"
+	"github.com/AJMerr/hydianflow/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
 
 type Handler struct {
-	DB *gorm.DB
+	DB  *gorm.DB
+	Hub *realtime.Hub
+	GH  *githubapi.Service
+}
+
+// loadProject fetches the project named in the URL and checks the caller
+// holds at least min on it. On failure the error response has already been written.
+func (h *Handler) loadProject(w http.ResponseWriter, r *http.Request, uid uint, min database.ProjectRole) (database.Project, database.ProjectRole, bool) {
+	id64, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id64 == 0 {
+		utils.Error(w, http.StatusBadRequest, "bad_id", "invalid project id")
+		return database.Project{}, "", false
+	}
+	p, role, err := access.Require(h.DB, uint(id64), uid, min)
+	if err != nil {
+		access.WriteError(w, err, "project not found")
+		return p, role, false
+	}
+	return p, role, true
 }
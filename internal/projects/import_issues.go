@@ -0,0 +1,289 @@
+package projects
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/AJMerr/hydianflow/internal/auth"
+	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/githubhttp"
+	"github.com/AJMerr/hydianflow/internal/history"
+	"github.com/AJMerr/hydianflow/internal/realtime"
+	"github.com/AJMerr/hydianflow/internal/utils"
+	"github.com/AJMerr/hydianflow/internal/workflow"
+	"github.com/google/go-github/v74/github"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Imports stop after this many issues; run again to pick up the rest
+const maxImportIssues = 1000
+
+type ImportResult struct {
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"`
+	// GitHub labels left off because the project hit its label limit
+	LabelsSkipped []string `json:"labels_skipped,omitempty"`
+	TaskIDs       []uint   `json:"task_ids"`
+}
+
+// POST /api/v1/projects/{id}/import/github-issues?repo_full_name=&state=
+//
+// Creates a task for every issue in the repository that isn't linked to one
+// of the project's tasks yet. GitHub labels become project labels, created
+// as needed, and assignees are matched to project members by GitHub login.
+func (h *Handler) ImportGitHubIssues(w http.ResponseWriter, r *http.Request) {
+	uid, ok := auth.UserIDFromCtx(r.Context())
+	if !ok || uid == 0 {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "auth required")
+		return
+	}
+
+	p, _, ok := h.loadProject(w, r, uid, database.ProjectRoleMember)
+	if !ok {
+		return
+	}
+
+	repo := strings.TrimSpace(r.URL.Query().Get("repo_full_name"))
+	if owner, name, ok := strings.Cut(repo, "/"); !ok || owner == "" || name == "" {
+		utils.Error(w, http.StatusBadRequest, "validation", "repo_full_name must be owner/repo")
+		return
+	}
+	state := r.URL.Query().Get("state")
+	switch state {
+	case "":
+		state = "open"
+	case "open", "all":
+	default:
+		utils.Error(w, http.StatusBadRequest, "validation", "state must be open or all")
+		return
+	}
+
+	var issues []*github.Issue
+	for page := 1; page != 0 && len(issues) < maxImportIssues; {
+		batch, resp, err := h.GH.ListIssues(r.Context(), uid, repo, state, page, 100)
+		if err != nil {
+			githubhttp.WriteError(w, err, nil)
+			return
+		}
+		issues = append(issues, batch...)
+		page = resp.NextPage
+	}
+	if len(issues) > maxImportIssues {
+		issues = issues[:maxImportIssues]
+	}
+
+	out := ImportResult{TaskIDs: []uint{}}
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		var linked []int
+		if err := tx.Model(&database.Task{}).
+			Where("project_id = ? AND repo_full_name = ? AND issue_number IS NOT NULL", p.ID, repo).
+			Pluck("issue_number", &linked).Error; err != nil {
+			return err
+		}
+		seen := make(map[int]bool, len(linked))
+		for _, n := range linked {
+			seen[n] = true
+		}
+
+		var fresh []*github.Issue
+		logins := map[string]bool{}
+		for _, is := range issues {
+			if seen[is.GetNumber()] {
+				out.Skipped++
+				continue
+			}
+			seen[is.GetNumber()] = true
+			fresh = append(fresh, is)
+			for _, a := range is.Assignees {
+				logins[strings.ToLower(a.GetLogin())] = true
+			}
+		}
+		if len(fresh) == 0 {
+			return nil
+		}
+
+		members, err := memberLogins(tx, p, logins)
+		if err != nil {
+			return err
+		}
+		labels, skipped, err := ensureLabels(tx, p.ID, fresh)
+		if err != nil {
+			return err
+		}
+		out.LabelsSkipped = skipped
+
+		wf, err := workflow.ForProject(tx, &p.ID)
+		if err != nil {
+			return err
+		}
+		positions := map[string]float64{}
+		nextPosition := func(status string) (float64, error) {
+			if _, ok := positions[status]; !ok {
+				var maxPos float64
+				if err := tx.Model(&database.Task{}).
+					Where("project_id = ? AND status = ?", p.ID, status).
+					Select("COALESCE(MAX(position), 0)").
+					Scan(&maxPos).Error; err != nil {
+					return 0, err
+				}
+				positions[status] = maxPos
+			}
+			positions[status] += 1000
+			return positions[status], nil
+		}
+
+		now := time.Now().UTC()
+		actor := history.UserActor(uid)
+		for _, is := range fresh {
+			status := wf.First(database.StatusCategoryTodo)
+			if is.GetState() == "closed" {
+				status = wf.First(database.StatusCategoryDone)
+			}
+			pos, err := nextPosition(status)
+			if err != nil {
+				return err
+			}
+
+			projectID := p.ID
+			number := is.GetNumber()
+			repoName := repo
+			t := database.Task{
+				Title:       is.GetTitle(),
+				Description: is.GetBody(),
+				Status:      database.TaskStatus(status),
+				Position:    pos,
+				CreatorID:   uid,
+				ProjectID:   &projectID,
+				RepoName:    &repoName,
+				IssueNumber: &number,
+			}
+			for _, a := range is.Assignees {
+				if id, ok := members[strings.ToLower(a.GetLogin())]; ok {
+					t.AssigneeID = &id
+					break
+				}
+			}
+			var labelIDs []uint
+			for _, l := range is.Labels {
+				name := strings.ToLower(strings.TrimSpace(l.GetName()))
+				if id, ok := labels[name]; ok {
+					labelIDs = append(labelIDs, id)
+				}
+			}
+			wf.Stamp(&t, now)
+
+			res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&t)
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				out.Skipped++
+				continue
+			}
+			for _, id := range labelIDs {
+				if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+					Create(&database.TaskLabel{TaskID: t.ID, LabelID: id}).Error; err != nil {
+					return err
+				}
+			}
+			if err := history.Record(tx, t.ID, actor, history.Change{
+				Field:    history.FieldCreated,
+				NewValue: history.Str(t.Title),
+			}); err != nil {
+				return err
+			}
+			if err := realtime.PublishTask(tx, realtime.TaskCreated, realtime.SourceUser, t); err != nil {
+				return err
+			}
+			out.Imported++
+			out.TaskIDs = append(out.TaskIDs, t.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_import", "failed to import issues")
+		return
+	}
+	utils.JSON(w, http.StatusOK, out)
+}
+
+// memberLogins maps the lowercased GitHub logins of project members among
+// logins to their user ids.
+func memberLogins(tx *gorm.DB, p database.Project, logins map[string]bool) (map[string]uint, error) {
+	out := map[string]uint{}
+	if len(logins) == 0 {
+		return out, nil
+	}
+	list := make([]string, 0, len(logins))
+	for l := range logins {
+		list = append(list, l)
+	}
+	var users []database.User
+	if err := tx.Select("id, github_login").
+		Where("LOWER(github_login) IN ?", list).
+		Where("id = ? OR id IN (?)", p.OwnerID,
+			tx.Model(&database.ProjectMember{}).Select("user_id").Where("project_id = ?", p.ID)).
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		out[strings.ToLower(u.GitHubLogin)] = u.ID
+	}
+	return out, nil
+}
+
+// ensureLabels creates a project label for every GitHub label on issues
+// that the project doesn't have yet, up to the per-project limit, and
+// returns all of them by lowercased name along with the names left out.
+func ensureLabels(tx *gorm.DB, projectID uint, issues []*github.Issue) (map[string]uint, []string, error) {
+	var labels []database.Label
+	if err := tx.Where("project_id = ?", projectID).Find(&labels).Error; err != nil {
+		return nil, nil, err
+	}
+	out := make(map[string]uint, len(labels))
+	for _, l := range labels {
+		out[strings.ToLower(l.Name)] = l.ID
+	}
+
+	count := len(labels)
+	var skipped []string
+	seen := map[string]bool{}
+	for _, is := range issues {
+		for _, gl := range is.Labels {
+			name := strings.TrimSpace(gl.GetName())
+			key := strings.ToLower(name)
+			if name == "" || len(name) > maxLabelNameLen || seen[key] {
+				continue
+			}
+			seen[key] = true
+			if _, ok := out[key]; ok {
+				continue
+			}
+			if count >= maxLabelsPerBoard {
+				skipped = append(skipped, name)
+				continue
+			}
+			color := "#" + strings.ToLower(gl.GetColor())
+			if !labelColorRe.MatchString(color) {
+				color = defaultLabelColor
+			}
+			l := database.Label{ProjectID: projectID, Name: name, Color: color}
+			res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&l)
+			if res.Error != nil {
+				return nil, nil, res.Error
+			}
+			if res.RowsAffected == 0 {
+				// Created concurrently under the same name
+				if err := tx.Where("project_id = ? AND lower(name) = ?", projectID, key).First(&l).Error; err != nil {
+					return nil, nil, err
+				}
+			} else {
+				count++
+			}
+			out[key] = l.ID
+		}
+	}
+	return out, skipped, nil
+}
@@ -0,0 +1,277 @@
+package projects
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AJMerr/hydianflow/internal/access"
+	"github.com/AJMerr/hydianflow/internal/auth"
+	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultInviteTTL = 7 * 24 * time.Hour
+	maxInviteTTL     = 30 * 24 * time.Hour
+)
+
+type InvitationCreateRequest struct {
+	GithubLogin    string  `json:"github_login"`
+	Role           *string `json:"role,omitempty"`
+	ExpiresInHours *int    `json:"expires_in_hours,omitempty"`
+}
+
+type InvitationResp struct {
+	ID          uint       `json:"id"`
+	ProjectID   uint       `json:"project_id"`
+	GithubLogin string     `json:"github_login"`
+	Role        string     `json:"role"`
+	InvitedByID uint       `json:"invited_by_id"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	AcceptedAt  *time.Time `json:"accepted_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	// Only returned when the invitation is created
+	Token string `json:"token,omitempty"`
+}
+
+func toInvitationResp(inv database.ProjectInvitation) InvitationResp {
+	return InvitationResp{
+		ID:          inv.ID,
+		ProjectID:   inv.ProjectID,
+		GithubLogin: inv.GitHubLogin,
+		Role:        string(inv.Role),
+		InvitedByID: inv.InvitedByID,
+		ExpiresAt:   inv.ExpiresAt,
+		AcceptedAt:  inv.AcceptedAt,
+		CreatedAt:   inv.CreatedAt,
+	}
+}
+
+// POST /api/v1/projects/{id}/invitations
+func (h *Handler) CreateInvitation(w http.ResponseWriter, r *http.Request) {
+	uid, ok := auth.UserIDFromCtx(r.Context())
+	if !ok || uid == 0 {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "auth required")
+		return
+	}
+
+	p, callerRole, ok := h.loadProject(w, r, uid, database.ProjectRoleAdmin)
+	if !ok {
+		return
+	}
+
+	var req InvitationCreateRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		utils.Error(w, http.StatusBadRequest, "bad_json", "invalid json")
+		return
+	}
+
+	login := strings.TrimPrefix(strings.TrimSpace(req.GithubLogin), "@")
+	if login == "" {
+		utils.Error(w, http.StatusBadRequest, "validation", "github_login is required")
+		return
+	}
+	role := database.ProjectRoleMember
+	if req.Role != nil {
+		nr, ok := normalRole(*req.Role)
+		if !ok {
+			utils.Error(w, http.StatusBadRequest, "validation", "invalid role")
+			return
+		}
+		role = nr
+	}
+	if !canGrant(callerRole, role) {
+		utils.Error(w, http.StatusForbidden, "forbidden", "cannot grant that role")
+		return
+	}
+	ttl := defaultInviteTTL
+	if req.ExpiresInHours != nil {
+		ttl = time.Duration(*req.ExpiresInHours) * time.Hour
+		if ttl <= 0 || ttl > maxInviteTTL {
+			utils.Error(w, http.StatusBadRequest, "validation", "expires_in_hours must be between 1 and 720")
+			return
+		}
+	}
+
+	// Users who already signed in are added directly via /members
+	var existing int64
+	if err := h.DB.Model(&database.User{}).
+		Where("LOWER(github_login) = LOWER(?)", login).
+		Count(&existing).Error; err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db", "failed to look up user")
+		return
+	}
+	if existing > 0 {
+		utils.Error(w, http.StatusConflict, "user_exists", "user already has an account; add them as a member")
+		return
+	}
+
+	now := time.Now().UTC()
+	inv := database.ProjectInvitation{
+		ProjectID:   p.ID,
+		GitHubLogin: login,
+		Role:        role,
+		Token:       inviteToken(),
+		InvitedByID: uid,
+		ExpiresAt:   now.Add(ttl),
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		// Expired invitations no longer block a fresh one
+		if err := tx.
+			Where("project_id = ? AND LOWER(github_login) = LOWER(?) AND accepted_at IS NULL AND expires_at <= ?", p.ID, login, now).
+			Delete(&database.ProjectInvitation{}).Error; err != nil {
+			return err
+		}
+		var open int64
+		if err := tx.Model(&database.ProjectInvitation{}).
+			Where("project_id = ? AND LOWER(github_login) = LOWER(?) AND accepted_at IS NULL", p.ID, login).
+			Count(&open).Error; err != nil {
+			return err
+		}
+		if open > 0 {
+			return errInviteExists
+		}
+		return tx.Create(&inv).Error
+	})
+	if err != nil {
+		if errors.Is(err, errInviteExists) {
+			utils.Error(w, http.StatusConflict, "already_invited", "an invitation for this login is already pending")
+			return
+		}
+		utils.Error(w, http.StatusInternalServerError, "db_create", "failed to create invitation")
+		return
+	}
+
+	out := toInvitationResp(inv)
+	out.Token = inv.Token
+	utils.JSON(w, http.StatusCreated, out)
+}
+
+// GET /api/v1/projects/{id}/invitations
+func (h *Handler) ListInvitations(w http.ResponseWriter, r *http.Request) {
+	uid, ok := auth.UserIDFromCtx(r.Context())
+	if !ok || uid == 0 {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "auth required")
+		return
+	}
+
+	p, _, ok := h.loadProject(w, r, uid, database.ProjectRoleAdmin)
+	if !ok {
+		return
+	}
+
+	q := h.DB.Where("project_id = ?", p.ID)
+	if r.URL.Query().Get("all") != "1" {
+		q = q.Where("accepted_at IS NULL AND expires_at > ?", time.Now().UTC())
+	}
+	var rows []database.ProjectInvitation
+	if err := q.Order("created_at DESC").Find(&rows).Error; err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_list", "failed to load invitations")
+		return
+	}
+
+	out := make([]InvitationResp, len(rows))
+	for i := range rows {
+		out[i] = toInvitationResp(rows[i])
+	}
+	utils.JSON(w, http.StatusOK, out)
+}
+
+// DELETE /api/v1/projects/{id}/invitations/{invitationID}
+func (h *Handler) RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	uid, ok := auth.UserIDFromCtx(r.Context())
+	if !ok || uid == 0 {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "auth required")
+		return
+	}
+
+	p, callerRole, ok := h.loadProject(w, r, uid, database.ProjectRoleAdmin)
+	if !ok {
+		return
+	}
+
+	invID, err := strconv.ParseUint(chi.URLParam(r, "invitationID"), 10, 64)
+	if err != nil || invID == 0 {
+		utils.Error(w, http.StatusBadRequest, "bad_id", "invalid invitation id")
+		return
+	}
+
+	var inv database.ProjectInvitation
+	if err := h.DB.Where("id = ? AND project_id = ? AND accepted_at IS NULL", invID, p.ID).First(&inv).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.Error(w, http.StatusNotFound, "not_found", "invitation not found")
+			return
+		}
+		utils.Error(w, http.StatusInternalServerError, "db", "failed to load invitation")
+		return
+	}
+	if !canGrant(callerRole, inv.Role) {
+		utils.Error(w, http.StatusForbidden, "forbidden", "insufficient project role")
+		return
+	}
+
+	if err := h.DB.Delete(&inv).Error; err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_delete", "failed to revoke invitation")
+		return
+	}
+	utils.JSON(w, http.StatusOK, map[string]string{"ok": "revoked"})
+}
+
+// POST /api/v1/projects/invitations/{token}/accept
+func (h *Handler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	uid, ok := auth.UserIDFromCtx(r.Context())
+	if !ok || uid == 0 {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "auth required")
+		return
+	}
+
+	var u database.User
+	if err := h.DB.Select("id", "github_login").First(&u, uid).Error; err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db", "failed to load user")
+		return
+	}
+
+	now := time.Now().UTC()
+	var inv database.ProjectInvitation
+	if err := h.DB.
+		Where("token = ? AND accepted_at IS NULL AND expires_at > ?", chi.URLParam(r, "token"), now).
+		First(&inv).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.Error(w, http.StatusNotFound, "not_found", "invitation not found or expired")
+			return
+		}
+		utils.Error(w, http.StatusInternalServerError, "db", "failed to load invitation")
+		return
+	}
+	if !strings.EqualFold(inv.GitHubLogin, u.GitHubLogin) {
+		utils.Error(w, http.StatusForbidden, "forbidden", "invitation is for a different GitHub account")
+		return
+	}
+
+	if err := h.DB.Transaction(func(tx *gorm.DB) error {
+		return access.AcceptInvitation(tx, inv, uid, now)
+	}); err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db", "failed to accept invitation")
+		return
+	}
+	utils.JSON(w, http.StatusOK, map[string]any{"project_id": inv.ProjectID, "role": inv.Role})
+}
+
+var errInviteExists = errors.New("invitation already pending")
+
+func inviteToken() string {
+	b := make([]byte, 24)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,214 @@
+package projects
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/AJMerr/hydianflow/internal/auth"
+	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxLabelNameLen   = 50
+	maxLabelsPerBoard = 200
+	defaultLabelColor = "#6b7280"
+)
+
+var labelColorRe = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+type LabelRequest struct {
+	Name  *string `json:"name,omitempty"`
+	Color *string `json:"color,omitempty"`
+}
+
+// GET /api/v1/projects/{id}/labels
+func (h *Handler) ListLabels(w http.ResponseWriter, r *http.Request) {
+	uid, ok := auth.UserIDFromCtx(r.Context())
+	if !ok || uid == 0 {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "auth required")
+		return
+	}
+
+	p, _, ok := h.loadProject(w, r, uid, database.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+
+	labels := []database.Label{}
+	if err := h.DB.Where("project_id = ?", p.ID).
+		Order("lower(name) ASC, id ASC").
+		Find(&labels).Error; err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db", "failed to load labels")
+		return
+	}
+	utils.JSON(w, http.StatusOK, labels)
+}
+
+// POST /api/v1/projects/{id}/labels
+func (h *Handler) CreateLabel(w http.ResponseWriter, r *http.Request) {
+	uid, ok := auth.UserIDFromCtx(r.Context())
+	if !ok || uid == 0 {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "auth required")
+		return
+	}
+
+	p, _, ok := h.loadProject(w, r, uid, database.ProjectRoleMember)
+	if !ok {
+		return
+	}
+
+	req, ok := decodeLabel(w, r)
+	if !ok {
+		return
+	}
+	if req.Name == nil {
+		utils.Error(w, http.StatusBadRequest, "validation", "name is required")
+		return
+	}
+
+	l := database.Label{ProjectID: p.ID, Name: *req.Name, Color: defaultLabelColor}
+	if req.Color != nil {
+		l.Color = *req.Color
+	}
+
+	var n int64
+	if err := h.DB.Model(&database.Label{}).Where("project_id = ?", p.ID).Count(&n).Error; err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db", "failed to count labels")
+		return
+	}
+	if n >= maxLabelsPerBoard {
+		utils.Error(w, http.StatusBadRequest, "validation", "project has too many labels")
+		return
+	}
+
+	// Names are unique per project, case-insensitively
+	res := h.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&l)
+	if res.Error != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_create", "failed to create label")
+		return
+	}
+	if res.RowsAffected == 0 {
+		utils.Error(w, http.StatusConflict, "label_exists", "a label with that name already exists")
+		return
+	}
+	utils.JSON(w, http.StatusCreated, l)
+}
+
+// PATCH /api/v1/projects/{id}/labels/{labelID}
+func (h *Handler) UpdateLabel(w http.ResponseWriter, r *http.Request) {
+	uid, ok := auth.UserIDFromCtx(r.Context())
+	if !ok || uid == 0 {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "auth required")
+		return
+	}
+
+	p, _, ok := h.loadProject(w, r, uid, database.ProjectRoleMember)
+	if !ok {
+		return
+	}
+	l, ok := h.loadLabel(w, r, p)
+	if !ok {
+		return
+	}
+
+	req, ok := decodeLabel(w, r)
+	if !ok {
+		return
+	}
+	if req.Name != nil {
+		l.Name = *req.Name
+	}
+	if req.Color != nil {
+		l.Color = *req.Color
+	}
+
+	// Names are unique per project, case-insensitively
+	if err := h.DB.Save(&l).Error; err != nil {
+		if database.IsUniqueViolation(err) {
+			utils.Error(w, http.StatusConflict, "label_exists", "a label with that name already exists")
+			return
+		}
+		utils.Error(w, http.StatusInternalServerError, "db_update", "failed to update label")
+		return
+	}
+	utils.JSON(w, http.StatusOK, l)
+}
+
+// DELETE /api/v1/projects/{id}/labels/{labelID}
+//
+// Removes the label from every task that carries it.
+func (h *Handler) DeleteLabel(w http.ResponseWriter, r *http.Request) {
+	uid, ok := auth.UserIDFromCtx(r.Context())
+	if !ok || uid == 0 {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "auth required")
+		return
+	}
+
+	p, _, ok := h.loadProject(w, r, uid, database.ProjectRoleAdmin)
+	if !ok {
+		return
+	}
+	l, ok := h.loadLabel(w, r, p)
+	if !ok {
+		return
+	}
+
+	if err := h.DB.Delete(&l).Error; err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_delete", "failed to delete label")
+		return
+	}
+	utils.JSON(w, http.StatusOK, map[string]string{"ok": "true"})
+}
+
+func decodeLabel(w http.ResponseWriter, r *http.Request) (LabelRequest, bool) {
+	var req LabelRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		utils.Error(w, http.StatusBadRequest, "bad_json", "invalid JSON body")
+		return req, false
+	}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" || len(name) > maxLabelNameLen {
+			utils.Error(w, http.StatusBadRequest, "validation", "name must be 1-50 characters")
+			return req, false
+		}
+		req.Name = &name
+	}
+	if req.Color != nil {
+		color := strings.ToLower(strings.TrimSpace(*req.Color))
+		if !labelColorRe.MatchString(color) {
+			utils.Error(w, http.StatusBadRequest, "validation", "color must be a hex value like #1f883d")
+			return req, false
+		}
+		req.Color = &color
+	}
+	return req, true
+}
+
+func (h *Handler) loadLabel(w http.ResponseWriter, r *http.Request, p database.Project) (database.Label, bool) {
+	var l database.Label
+	lid, err := strconv.ParseUint(chi.URLParam(r, "labelID"), 10, 64)
+	if err != nil || lid == 0 {
+		utils.Error(w, http.StatusBadRequest, "bad_id", "invalid label id")
+		return l, false
+	}
+	if err := h.DB.Where("id = ? AND project_id = ?", lid, p.ID).First(&l).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.Error(w, http.StatusNotFound, "not_found", "label not found")
+			return l, false
+		}
+		utils.Error(w, http.StatusInternalServerError, "db", "failed to load label")
+		return l, false
+	}
+	return l, true
+}
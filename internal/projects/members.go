@@ -1,12 +1,19 @@
 package projects
 
 import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/AJMerr/hydianflow/internal/access"
 	"github.com/AJMerr/hydianflow/internal/auth"
 	"github.com/AJMerr/hydianflow/internal/database"
 	"github.com/AJMerr/hydianflow/internal/utils"
 	"github.com/go-chi/chi/v5"
 	"gorm.io/gorm"
-	"net/http"
+	"gorm.io/gorm/clause"
 )
 
 type MemberResp struct {
@@ -14,6 +21,17 @@ type MemberResp struct {
 	Name        string `json:"name"`
 	AvatarURL   string `json:"avatar_url"`
 	GithubLogin string `json:"github_login"`
+	Role        string `json:"role"`
+}
+
+type MemberAddRequest struct {
+	UserID      *uint   `json:"user_id,omitempty"`
+	GithubLogin *string `json:"github_login,omitempty"`
+	Role        *string `json:"role,omitempty"`
+}
+
+type MemberUpdateRequest struct {
+	Role string `json:"role"`
 }
 
 func (h *Handler) ListMembers(w http.ResponseWriter, r *http.Request) {
@@ -23,15 +41,9 @@ func (h *Handler) ListMembers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pid := chi.URLParam(r, "id")
-	// owner check (only list members of your own project)
-	var p database.Project
-	if err := h.DB.Where("id = ? AND owner_id = ?", pid, uid).First(&p).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			utils.Error(w, http.StatusNotFound, "not_found", "project not found")
-			return
-		}
-		utils.Error(w, http.StatusInternalServerError, "db", "failed to load project")
+	// any member may see who else is on the project
+	p, _, ok := h.loadProject(w, r, uid, database.ProjectRoleViewer)
+	if !ok {
 		return
 	}
 
@@ -41,13 +53,14 @@ func (h *Handler) ListMembers(w http.ResponseWriter, r *http.Request) {
 		Name        string
 		AvatarURL   string
 		GithubLogin string
+		Role        string
 	}
 	var rows []row
 	if err := h.DB.
 		Table("project_members pm").
-		Select("u.id, u.name, u.avatar_url, u.github_login").
+		Select("u.id, u.name, u.avatar_url, u.github_login, pm.role").
 		Joins("JOIN users u ON u.id = pm.user_id").
-		Where("pm.project_id = ?", pid).
+		Where("pm.project_id = ?", p.ID).
 		Scan(&rows).Error; err != nil {
 		utils.Error(w, http.StatusInternalServerError, "db", "failed to load members")
 		return
@@ -55,7 +68,211 @@ func (h *Handler) ListMembers(w http.ResponseWriter, r *http.Request) {
 
 	out := make([]MemberResp, len(rows))
 	for i, r0 := range rows {
-		out[i] = MemberResp{ID: r0.ID, Name: r0.Name, AvatarURL: r0.AvatarURL, GithubLogin: r0.GithubLogin}
+		out[i] = MemberResp{ID: r0.ID, Name: r0.Name, AvatarURL: r0.AvatarURL, GithubLogin: r0.GithubLogin, Role: r0.Role}
 	}
 	utils.JSON(w, http.StatusOK, out)
 }
+
+// POST /api/v1/projects/{id}/members
+func (h *Handler) AddMember(w http.ResponseWriter, r *http.Request) {
+	uid, ok := auth.UserIDFromCtx(r.Context())
+	if !ok || uid == 0 {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "auth required")
+		return
+	}
+
+	p, callerRole, ok := h.loadProject(w, r, uid, database.ProjectRoleAdmin)
+	if !ok {
+		return
+	}
+
+	var req MemberAddRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		utils.Error(w, http.StatusBadRequest, "bad_json", "invalid json")
+		return
+	}
+
+	role := database.ProjectRoleMember
+	if req.Role != nil {
+		nr, ok := normalRole(*req.Role)
+		if !ok {
+			utils.Error(w, http.StatusBadRequest, "validation", "invalid role")
+			return
+		}
+		role = nr
+	}
+	if !canGrant(callerRole, role) {
+		utils.Error(w, http.StatusForbidden, "forbidden", "cannot grant that role")
+		return
+	}
+
+	var u database.User
+	q := h.DB.Select("id", "name", "avatar_url", "github_login")
+	switch {
+	case req.UserID != nil && *req.UserID != 0:
+		q = q.Where("id = ?", *req.UserID)
+	case req.GithubLogin != nil && strings.TrimSpace(*req.GithubLogin) != "":
+		login := strings.TrimPrefix(strings.TrimSpace(*req.GithubLogin), "@")
+		q = q.Where("LOWER(github_login) = LOWER(?)", login)
+	default:
+		utils.Error(w, http.StatusBadRequest, "validation", "user_id or github_login is required")
+		return
+	}
+	if err := q.First(&u).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.Error(w, http.StatusNotFound, "user_not_found", "user not found")
+			return
+		}
+		utils.Error(w, http.StatusInternalServerError, "db", "failed to load user")
+		return
+	}
+
+	if u.ID == p.OwnerID {
+		utils.Error(w, http.StatusConflict, "already_member", "user is already a member")
+		return
+	}
+
+	res := h.DB.
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&database.ProjectMember{ProjectID: p.ID, UserID: u.ID, Role: role})
+	if res.Error != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_create", "failed to add member")
+		return
+	}
+	if res.RowsAffected == 0 {
+		utils.Error(w, http.StatusConflict, "already_member", "user is already a member")
+		return
+	}
+
+	utils.JSON(w, http.StatusCreated, MemberResp{
+		ID:          u.ID,
+		Name:        u.Name,
+		AvatarURL:   u.AvatarURL,
+		GithubLogin: u.GitHubLogin,
+		Role:        string(role),
+	})
+}
+
+// PATCH /api/v1/projects/{id}/members/{userID}
+func (h *Handler) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	uid, ok := auth.UserIDFromCtx(r.Context())
+	if !ok || uid == 0 {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "auth required")
+		return
+	}
+
+	p, callerRole, ok := h.loadProject(w, r, uid, database.ProjectRoleAdmin)
+	if !ok {
+		return
+	}
+
+	var req MemberUpdateRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		utils.Error(w, http.StatusBadRequest, "bad_json", "invalid json")
+		return
+	}
+	role, ok := normalRole(req.Role)
+	if !ok {
+		utils.Error(w, http.StatusBadRequest, "validation", "invalid role")
+		return
+	}
+
+	m, ok := h.loadMember(w, r, p)
+	if !ok {
+		return
+	}
+	if m.Role == database.ProjectRoleOwner {
+		utils.Error(w, http.StatusBadRequest, "validation", "owner role cannot be changed")
+		return
+	}
+	if !canGrant(callerRole, m.Role) || !canGrant(callerRole, role) {
+		utils.Error(w, http.StatusForbidden, "forbidden", "cannot grant that role")
+		return
+	}
+
+	if err := h.DB.Model(&database.ProjectMember{}).
+		Where("project_id = ? AND user_id = ?", m.ProjectID, m.UserID).
+		Update("role", role).Error; err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_update", "failed to update member")
+		return
+	}
+	utils.JSON(w, http.StatusOK, map[string]any{"user_id": m.UserID, "role": role})
+}
+
+// DELETE /api/v1/projects/{id}/members/{userID}
+func (h *Handler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	uid, ok := auth.UserIDFromCtx(r.Context())
+	if !ok || uid == 0 {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "auth required")
+		return
+	}
+
+	// Anyone may leave a project; removing others needs owner/admin
+	p, callerRole, ok := h.loadProject(w, r, uid, database.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+
+	m, ok := h.loadMember(w, r, p)
+	if !ok {
+		return
+	}
+	if m.Role == database.ProjectRoleOwner || m.UserID == p.OwnerID {
+		utils.Error(w, http.StatusBadRequest, "validation", "owner cannot be removed")
+		return
+	}
+	if m.UserID != uid {
+		if !access.AtLeast(callerRole, database.ProjectRoleAdmin) || !canGrant(callerRole, m.Role) {
+			utils.Error(w, http.StatusForbidden, "forbidden", "insufficient project role")
+			return
+		}
+	}
+
+	if err := h.DB.
+		Where("project_id = ? AND user_id = ?", m.ProjectID, m.UserID).
+		Delete(&database.ProjectMember{}).Error; err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_delete", "failed to remove member")
+		return
+	}
+	utils.JSON(w, http.StatusOK, map[string]string{"ok": "removed"})
+}
+
+func (h *Handler) loadMember(w http.ResponseWriter, r *http.Request, p database.Project) (database.ProjectMember, bool) {
+	var m database.ProjectMember
+	memberID, err := strconv.ParseUint(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil || memberID == 0 {
+		utils.Error(w, http.StatusBadRequest, "bad_id", "invalid user id")
+		return m, false
+	}
+	if err := h.DB.Where("project_id = ? AND user_id = ?", p.ID, uint(memberID)).First(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.Error(w, http.StatusNotFound, "not_found", "member not found")
+			return m, false
+		}
+		utils.Error(w, http.StatusInternalServerError, "db", "failed to load member")
+		return m, false
+	}
+	return m, true
+}
+
+// normalRole parses an assignable role; owner is never assignable.
+func normalRole(s string) (database.ProjectRole, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if !access.ValidRole(s) || s == string(database.ProjectRoleOwner) {
+		return "", false
+	}
+	return database.ProjectRole(s), true
+}
+
+// canGrant reports whether caller may hand out (or take away) role.
+// Only the owner manages admins.
+func canGrant(caller, role database.ProjectRole) bool {
+	if role == database.ProjectRoleAdmin {
+		return caller == database.ProjectRoleOwner
+	}
+	return access.AtLeast(caller, database.ProjectRoleAdmin) && role != database.ProjectRoleOwner
+}
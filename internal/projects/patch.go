@@ -6,17 +6,20 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/AJMerr/hydianflow/internal/access"
 	"github.com/AJMerr/hydianflow/internal/auth"
 	"github.com/AJMerr/hydianflow/internal/database"
 	"github.com/AJMerr/hydianflow/internal/utils"
-	"github.com/go-chi/chi/v5"
-	"gorm.io/gorm"
 )
 
 type ProjectUpdateRequest struct {
 	Name        *string `json:"name,omitempty"`
 	Description *string `json:"description,omitempty"`
 	ParentID    *uint   `json:"parent_id,omitempty"`
+	// BlockedPolicy is "refuse" or "warn"
+	BlockedPolicy *string `json:"blocked_policy,omitempty"`
+	// SyncIssueState closes and reopens imported GitHub issues with their tasks
+	SyncIssueState *bool `json:"sync_issue_state,omitempty"`
 }
 
 func (h *Handler) Patch(w http.ResponseWriter, r *http.Request) {
@@ -25,7 +28,6 @@ func (h *Handler) Patch(w http.ResponseWriter, r *http.Request) {
 		utils.Error(w, http.StatusUnauthorized, "unauthorized", "auth is required")
 		return
 	}
-	idStr := chi.URLParam(r, "id")
 
 	var body ProjectUpdateRequest
 	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
@@ -35,13 +37,8 @@ func (h *Handler) Patch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var p database.Project
-	if err := h.DB.Where("id = ? AND owner_id = ?", idStr, uid).First(&p).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			utils.Error(w, http.StatusNotFound, "not_found", "project not found")
-			return
-		}
-		utils.Error(w, http.StatusInternalServerError, "db_get", "failed to load project")
+	p, _, ok := h.loadProject(w, r, uid, database.ProjectRoleAdmin)
+	if !ok {
 		return
 	}
 
@@ -66,12 +63,8 @@ func (h *Handler) Patch(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 
-			var parent database.Project
-			if err := h.DB.
-				Select("id").
-				Where("id = ? AND owner_id = ?", *body.ParentID, uid).
-				First(&parent).Error; err != nil {
-				if errors.Is(err, gorm.ErrRecordNotFound) {
+			if _, _, err := access.Require(h.DB, *body.ParentID, uid, database.ProjectRoleAdmin); err != nil {
+				if errors.Is(err, access.ErrNotFound) || errors.Is(err, access.ErrForbidden) {
 					utils.Error(w, http.StatusBadRequest, "validation", "parent project not found")
 					return
 				}
@@ -83,6 +76,20 @@ func (h *Handler) Patch(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if body.BlockedPolicy != nil {
+		switch bp := database.BlockedPolicy(strings.TrimSpace(*body.BlockedPolicy)); bp {
+		case database.BlockedPolicyRefuse, database.BlockedPolicyWarn:
+			p.BlockedPolicy = bp
+		default:
+			utils.Error(w, http.StatusBadRequest, "validation", "blocked_policy must be refuse or warn")
+			return
+		}
+	}
+
+	if body.SyncIssueState != nil {
+		p.SyncIssueState = *body.SyncIssueState
+	}
+
 	if err := h.DB.Save(&p).Error; err != nil {
 		utils.Error(w, http.StatusInternalServerError, "db_update", "failed to update database")
 		return
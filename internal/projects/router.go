@@ -4,16 +4,37 @@ import (
 	"net/http"
 
 	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/githubapi"
+	"github.com/AJMerr/hydianflow/internal/realtime"
 	"github.com/go-chi/chi/v5"
 )
 
-func Router(db *database.DB) http.Handler {
-	h := &Handler{DB: db.DB}
+func Router(db *database.DB, hub *realtime.Hub, gh *githubapi.Service) http.Handler {
+	h := &Handler{DB: db.DB, Hub: hub, GH: gh}
 	r := chi.NewRouter()
 
 	r.Get("/", h.List)
 	r.Get("/{id}", h.GetByID)
+	r.Get("/{id}/events", h.Events)
+	r.Get("/{id}/statuses", h.ListStatuses)
+	r.Put("/{id}/statuses", h.ReplaceStatuses)
+	r.Get("/{id}/labels", h.ListLabels)
+	r.Post("/{id}/labels", h.CreateLabel)
+	r.Patch("/{id}/labels/{labelID}", h.UpdateLabel)
+	r.Delete("/{id}/labels/{labelID}", h.DeleteLabel)
+	r.Get("/{id}/templates", h.ListTemplates)
+	r.Post("/{id}/templates", h.CreateTemplate)
+	r.Patch("/{id}/templates/{templateID}", h.UpdateTemplate)
+	r.Delete("/{id}/templates/{templateID}", h.DeleteTemplate)
+	r.Post("/{id}/import/github-issues", h.ImportGitHubIssues)
 	r.Get("/{id}/members", h.ListMembers)
+	r.Post("/{id}/members", h.AddMember)
+	r.Patch("/{id}/members/{userID}", h.UpdateMember)
+	r.Delete("/{id}/members/{userID}", h.RemoveMember)
+	r.Get("/{id}/invitations", h.ListInvitations)
+	r.Post("/{id}/invitations", h.CreateInvitation)
+	r.Delete("/{id}/invitations/{invitationID}", h.RevokeInvitation)
+	r.Post("/invitations/{token}/accept", h.AcceptInvitation)
 	r.Post("/", h.Create)
 	r.Patch("/{id}", h.Patch)
 	r.Delete("/{id}", h.Delete)
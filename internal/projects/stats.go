@@ -0,0 +1,135 @@
+package projects
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/AJMerr/hydianflow/internal/access"
+	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/workflow"
+	"gorm.io/gorm"
+)
+
+// TaskCounts summarizes the tasks on one or more boards.
+type TaskCounts struct {
+	Total      int                             `json:"total"`
+	ByStatus   map[string]int                  `json:"by_status"`
+	ByCategory map[database.StatusCategory]int `json:"by_category"`
+	// Open tasks past their due date
+	Overdue        int        `json:"overdue"`
+	LastActivityAt *time.Time `json:"last_activity_at"`
+}
+
+// ProjectStats covers the project's own tasks, with Rollup adding every
+// descendant project the caller can see.
+type ProjectStats struct {
+	TaskCounts
+	Rollup RollupStats `json:"rollup"`
+}
+
+type RollupStats struct {
+	TaskCounts
+	// Projects counts the project itself and its visible descendants
+	Projects int `json:"projects"`
+}
+
+func newTaskCounts() TaskCounts {
+	return TaskCounts{ByStatus: map[string]int{}, ByCategory: map[database.StatusCategory]int{}}
+}
+
+func (c *TaskCounts) add(status string, cat database.StatusCategory, n, overdue int, last *time.Time) {
+	if n > 0 {
+		c.Total += n
+		c.ByStatus[status] += n
+		c.ByCategory[cat] += n
+	}
+	c.Overdue += overdue
+	if last != nil && (c.LastActivityAt == nil || last.After(*c.LastActivityAt)) {
+		c.LastActivityAt = last
+	}
+}
+
+// wantsInclude reports whether the comma-separated ?include= lists name.
+func wantsInclude(r *http.Request, name string) bool {
+	for _, v := range strings.Split(r.URL.Query().Get("include"), ",") {
+		if strings.TrimSpace(v) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// loadStats computes stats for each of ids in one pass. The descendant tree
+// is walked with a recursive CTE, pruned to projects uid can see.
+func loadStats(db *gorm.DB, uid uint, ids []uint) (map[uint]*ProjectStats, error) {
+	out := make(map[uint]*ProjectStats, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+
+	type row struct {
+		RootID       uint
+		ProjectID    uint
+		Status       *string
+		N            int
+		Overdue      int
+		LastActivity *time.Time
+	}
+	var rows []row
+	// Deleted tasks don't count but still mark activity
+	if err := db.Raw(`
+		WITH RECURSIVE tree AS (
+			SELECT id AS root_id, id AS project_id
+			FROM projects
+			WHERE id IN ? AND deleted_at IS NULL
+			UNION
+			SELECT tree.root_id, p.id
+			FROM projects p
+			JOIN tree ON p.parent_id = tree.project_id
+			WHERE p.deleted_at IS NULL AND p.id IN (?)
+		)
+		SELECT tree.root_id, tree.project_id, t.status,
+		       COUNT(t.id) FILTER (WHERE t.deleted_at IS NULL) AS n,
+		       COUNT(t.id) FILTER (WHERE t.deleted_at IS NULL AND t.completed_at IS NULL AND t.due_at < now()) AS overdue,
+		       MAX(GREATEST(t.updated_at, t.deleted_at)) AS last_activity
+		FROM tree
+		LEFT JOIN tasks t ON t.project_id = tree.project_id
+		GROUP BY tree.root_id, tree.project_id, t.status`,
+		ids, access.ProjectIDs(db, uid)).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	workflows := workflow.NewCache(db)
+	projects := make(map[uint]map[uint]bool, len(ids))
+	for _, r := range rows {
+		s, ok := out[r.RootID]
+		if !ok {
+			s = &ProjectStats{TaskCounts: newTaskCounts(), Rollup: RollupStats{TaskCounts: newTaskCounts()}}
+			out[r.RootID] = s
+			projects[r.RootID] = map[uint]bool{}
+		}
+		projects[r.RootID][r.ProjectID] = true
+
+		var status string
+		var cat database.StatusCategory
+		if r.Status != nil {
+			status = *r.Status
+			pid := r.ProjectID
+			wf, err := workflows.Get(&pid)
+			if err != nil {
+				return nil, err
+			}
+			cat, _ = wf.Category(status)
+		}
+		if r.ProjectID == r.RootID {
+			s.add(status, cat, r.N, r.Overdue, r.LastActivity)
+		}
+		s.Rollup.add(status, cat, r.N, r.Overdue, r.LastActivity)
+	}
+	for id, s := range out {
+		s.Rollup.Projects = len(projects[id])
+	}
+	return out, nil
+}
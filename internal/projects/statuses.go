@@ -0,0 +1,268 @@
+package projects
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/AJMerr/hydianflow/internal/auth"
+	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/history"
+	"github.com/AJMerr/hydianflow/internal/realtime"
+	"github.com/AJMerr/hydianflow/internal/utils"
+	"github.com/AJMerr/hydianflow/internal/workflow"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StatusesUpdateRequest struct {
+	Statuses []workflow.Status `json:"statuses"`
+	// Remap moves tasks off statuses that are being removed: old key -> new key
+	Remap map[string]string `json:"remap,omitempty"`
+}
+
+// GET /api/v1/projects/{id}/statuses
+func (h *Handler) ListStatuses(w http.ResponseWriter, r *http.Request) {
+	uid, ok := auth.UserIDFromCtx(r.Context())
+	if !ok || uid == 0 {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "auth required")
+		return
+	}
+
+	p, _, ok := h.loadProject(w, r, uid, database.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+
+	wf, err := workflow.ForProject(h.DB, &p.ID)
+	if err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db", "failed to load statuses")
+		return
+	}
+	utils.JSON(w, http.StatusOK, wf)
+}
+
+// PUT /api/v1/projects/{id}/statuses
+//
+// Replaces the project's workflow. Tasks sitting in a status that no longer
+// exists must be moved with remap, otherwise the request is rejected. Moved
+// tasks, and tasks whose status changes category, get their started/completed
+// stamps brought in line and are published to the board. Tasks that end up
+// done complete their parents and release their dependents as usual.
+func (h *Handler) ReplaceStatuses(w http.ResponseWriter, r *http.Request) {
+	uid, ok := auth.UserIDFromCtx(r.Context())
+	if !ok || uid == 0 {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "auth required")
+		return
+	}
+
+	p, _, ok := h.loadProject(w, r, uid, database.ProjectRoleAdmin)
+	if !ok {
+		return
+	}
+
+	var req StatusesUpdateRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		utils.Error(w, http.StatusBadRequest, "bad_json", "invalid JSON body")
+		return
+	}
+
+	next, msg := normalWorkflow(req.Statuses)
+	if msg != "" {
+		utils.Error(w, http.StatusBadRequest, "validation", msg)
+		return
+	}
+	for from, to := range req.Remap {
+		if _, ok := next.Category(to); !ok {
+			utils.Error(w, http.StatusBadRequest, "validation", "remap target "+to+" is not in the new workflow")
+			return
+		}
+		if _, ok := next.Category(from); ok {
+			utils.Error(w, http.StatusBadRequest, "validation", "remap source "+from+" is still in the new workflow")
+			return
+		}
+	}
+
+	now := time.Now().UTC()
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		// Locking the project holds off task inserts and project moves (their
+		// foreign key check needs the row) and other workflow replacements
+		// until the new statuses are in place
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			Take(&database.Project{}, p.ID).Error; err != nil {
+			return err
+		}
+
+		type inUse struct {
+			Status string
+			N      int64
+		}
+		var used []inUse
+		if err := tx.Table("tasks").
+			Select("status, COUNT(*) AS n").
+			Where("project_id = ? AND deleted_at IS NULL", p.ID).
+			Group("status").
+			Scan(&used).Error; err != nil {
+			return err
+		}
+		var orphaned []string
+		for _, u := range used {
+			if _, ok := next.Category(u.Status); ok {
+				continue
+			}
+			if _, ok := req.Remap[u.Status]; !ok {
+				orphaned = append(orphaned, u.Status)
+			}
+		}
+		if len(orphaned) > 0 {
+			return statusesInUseError(orphaned)
+		}
+
+		prev, err := workflow.ForProject(tx, &p.ID)
+		if err != nil {
+			return err
+		}
+		// Tasks in remapped statuses, or in statuses that changed category, need
+		// restamping
+		var affected []string
+		for from := range req.Remap {
+			affected = append(affected, from)
+		}
+		for _, s := range next.Statuses {
+			if cat, ok := prev.Category(s.Key); ok && cat != s.Category {
+				affected = append(affected, s.Key)
+			}
+		}
+
+		// The new statuses go in first: completing parents and releasing
+		// dependents read categories from the project's saved workflow
+		if err := tx.Where("project_id = ?", p.ID).Delete(&database.ProjectStatus{}).Error; err != nil {
+			return err
+		}
+		rows := make([]database.ProjectStatus, len(next.Statuses))
+		for i, s := range next.Statuses {
+			rows[i] = database.ProjectStatus{
+				ProjectID: p.ID,
+				Key:       s.Key,
+				Label:     s.Label,
+				Position:  i,
+				Category:  s.Category,
+			}
+		}
+		if err := tx.Create(&rows).Error; err != nil {
+			return err
+		}
+
+		var tasks []database.Task
+		if len(affected) > 0 {
+			if err := tx.Where("project_id = ? AND status IN ?", p.ID, affected).
+				Clauses(clause.Locking{Strength: "UPDATE"}).
+				Find(&tasks).Error; err != nil {
+				return err
+			}
+		}
+		actor := history.UserActor(uid)
+		var parents, doneIDs []uint
+		for _, t := range tasks {
+			before := t
+			if to, ok := req.Remap[string(t.Status)]; ok {
+				t.Status = database.TaskStatus(to)
+			}
+			next.Stamp(&t, now)
+			t.UpdatedAt = now
+			if err := tx.Model(&database.Task{}).Where("id = ?", t.ID).Updates(map[string]any{
+				"status":       t.Status,
+				"started_at":   t.StartedAt,
+				"completed_at": t.CompletedAt,
+				"updated_at":   now,
+			}).Error; err != nil {
+				return err
+			}
+			if err := history.Record(tx, t.ID, actor, history.Diff(before, t)...); err != nil {
+				return err
+			}
+			if err := realtime.PublishTask(tx, realtime.TaskUpdated, realtime.SourceUser, t); err != nil {
+				return err
+			}
+			was, _ := prev.Category(string(before.Status))
+			if cat, _ := next.Category(string(t.Status)); cat == database.StatusCategoryDone && was != database.StatusCategoryDone {
+				doneIDs = append(doneIDs, t.ID)
+				if t.ParentTaskID != nil {
+					parents = append(parents, *t.ParentTaskID)
+				}
+			}
+		}
+
+		// Same follow-up as any other move into done
+		completed, err := workflow.CompleteParents(tx, actor, realtime.SourceUser, parents...)
+		if err != nil {
+			return err
+		}
+		_, err = workflow.ReleaseDependents(tx, actor, realtime.SourceUser, append(doneIDs, completed...))
+		return err
+	})
+	var inUse statusesInUseError
+	if errors.As(err, &inUse) {
+		utils.Error(w, http.StatusConflict, "statuses_in_use",
+			"tasks still use removed statuses: "+strings.Join(inUse, ", ")+" (add them to remap)")
+		return
+	}
+	if err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db", "failed to save statuses")
+		return
+	}
+
+	next.Custom = true
+	utils.JSON(w, http.StatusOK, next)
+}
+
+// statusesInUseError lists removed statuses that tasks still use and that
+// the request didn't remap.
+type statusesInUseError []string
+
+func (e statusesInUseError) Error() string {
+	return "statuses in use: " + strings.Join(e, ", ")
+}
+
+// normalWorkflow validates a submitted workflow. It returns a non-empty
+// message describing the first problem found.
+func normalWorkflow(in []workflow.Status) (workflow.Workflow, string) {
+	if len(in) == 0 {
+		return workflow.Workflow{}, "at least one status is required"
+	}
+	if len(in) > 20 {
+		return workflow.Workflow{}, "too many statuses"
+	}
+
+	seen := make(map[string]struct{}, len(in))
+	cats := make(map[database.StatusCategory]bool, 3)
+	out := workflow.Workflow{Statuses: make([]workflow.Status, len(in))}
+	for i, s := range in {
+		s.Key = strings.ToLower(strings.TrimSpace(s.Key))
+		s.Label = strings.TrimSpace(s.Label)
+		if !workflow.ValidKey(s.Key) {
+			return out, "invalid status key " + s.Key
+		}
+		if _, dup := seen[s.Key]; dup {
+			return out, "duplicate status key " + s.Key
+		}
+		seen[s.Key] = struct{}{}
+		if !workflow.ValidCategory(s.Category) {
+			return out, "invalid category for " + s.Key
+		}
+		if s.Label == "" {
+			s.Label = s.Key
+		}
+		cats[s.Category] = true
+		out.Statuses[i] = s
+	}
+	if !cats[database.StatusCategoryTodo] || !cats[database.StatusCategoryDone] {
+		return out, "workflow needs at least one todo and one done status"
+	}
+	return out, ""
+}
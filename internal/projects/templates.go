@@ -0,0 +1,295 @@
+package projects
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AJMerr/hydianflow/internal/access"
+	"github.com/AJMerr/hydianflow/internal/auth"
+	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/templates"
+	"github.com/AJMerr/hydianflow/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+const maxTemplatesPerProject = 50
+
+// TemplateRequest creates or patches a recurring task template. On patch,
+// "" clears priority, 0 clears estimate_points and -1 clears due_in_days.
+type TemplateRequest struct {
+	Title          *string `json:"title,omitempty"`
+	Description    *string `json:"description,omitempty"`
+	AssigneeID     *uint   `json:"assignee_id,omitempty"`
+	Priority       *string `json:"priority,omitempty"`
+	EstimatePoints *int    `json:"estimate_points,omitempty"`
+	LabelIDs       *[]uint `json:"label_ids,omitempty"`
+	DueInDays      *int    `json:"due_in_days,omitempty"`
+	Recurrence     *string `json:"recurrence,omitempty"`
+	Timezone       *string `json:"timezone,omitempty"`
+	Enabled        *bool   `json:"enabled,omitempty"`
+}
+
+// GET /api/v1/projects/{id}/templates
+func (h *Handler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	uid, ok := auth.UserIDFromCtx(r.Context())
+	if !ok || uid == 0 {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "auth required")
+		return
+	}
+
+	p, _, ok := h.loadProject(w, r, uid, database.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+
+	out := []database.TaskTemplate{}
+	if err := h.DB.Where("project_id = ?", p.ID).Order("id").Find(&out).Error; err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db", "failed to load templates")
+		return
+	}
+	utils.JSON(w, http.StatusOK, out)
+}
+
+// POST /api/v1/projects/{id}/templates
+func (h *Handler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	uid, ok := auth.UserIDFromCtx(r.Context())
+	if !ok || uid == 0 {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "auth required")
+		return
+	}
+
+	p, _, ok := h.loadProject(w, r, uid, database.ProjectRoleMember)
+	if !ok {
+		return
+	}
+
+	req, ok := decodeTemplate(w, r)
+	if !ok {
+		return
+	}
+	if req.Title == nil || req.Recurrence == nil {
+		utils.Error(w, http.StatusBadRequest, "validation", "title and recurrence are required")
+		return
+	}
+
+	var n int64
+	if err := h.DB.Model(&database.TaskTemplate{}).Where("project_id = ?", p.ID).Count(&n).Error; err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db", "failed to count templates")
+		return
+	}
+	if n >= maxTemplatesPerProject {
+		utils.Error(w, http.StatusBadRequest, "validation", "project has too many templates")
+		return
+	}
+
+	tpl := database.TaskTemplate{ProjectID: p.ID, CreatorID: uid, Timezone: "UTC", Enabled: true}
+	if !h.applyTemplate(w, &tpl, req) {
+		return
+	}
+	if err := h.DB.Create(&tpl).Error; err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_create", "failed to create template")
+		return
+	}
+	utils.JSON(w, http.StatusCreated, tpl)
+}
+
+// PATCH /api/v1/projects/{id}/templates/{templateID}
+func (h *Handler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	uid, ok := auth.UserIDFromCtx(r.Context())
+	if !ok || uid == 0 {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "auth required")
+		return
+	}
+
+	p, _, ok := h.loadProject(w, r, uid, database.ProjectRoleMember)
+	if !ok {
+		return
+	}
+	tpl, ok := h.loadTemplate(w, r, p)
+	if !ok {
+		return
+	}
+
+	req, ok := decodeTemplate(w, r)
+	if !ok {
+		return
+	}
+	if !h.applyTemplate(w, &tpl, req) {
+		return
+	}
+	if err := h.DB.Save(&tpl).Error; err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_update", "failed to update template")
+		return
+	}
+	utils.JSON(w, http.StatusOK, tpl)
+}
+
+// DELETE /api/v1/projects/{id}/templates/{templateID}
+//
+// Tasks already created from the template are kept.
+func (h *Handler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	uid, ok := auth.UserIDFromCtx(r.Context())
+	if !ok || uid == 0 {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "auth required")
+		return
+	}
+
+	p, _, ok := h.loadProject(w, r, uid, database.ProjectRoleMember)
+	if !ok {
+		return
+	}
+	tpl, ok := h.loadTemplate(w, r, p)
+	if !ok {
+		return
+	}
+
+	if err := h.DB.Delete(&tpl).Error; err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_delete", "failed to delete template")
+		return
+	}
+	utils.JSON(w, http.StatusOK, map[string]string{"ok": "true"})
+}
+
+func decodeTemplate(w http.ResponseWriter, r *http.Request) (TemplateRequest, bool) {
+	var req TemplateRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		utils.Error(w, http.StatusBadRequest, "bad_json", "invalid JSON body")
+		return req, false
+	}
+	if req.Title != nil {
+		title := strings.TrimSpace(*req.Title)
+		if title == "" {
+			utils.Error(w, http.StatusBadRequest, "validation", "title must not be empty")
+			return req, false
+		}
+		req.Title = &title
+	}
+	return req, true
+}
+
+// applyTemplate validates req and copies it onto tpl. The next run is
+// recomputed whenever the schedule changes or the template is re-enabled.
+func (h *Handler) applyTemplate(w http.ResponseWriter, tpl *database.TaskTemplate, req TemplateRequest) bool {
+	if req.Title != nil {
+		tpl.Title = *req.Title
+	}
+	if req.Description != nil {
+		tpl.Description = *req.Description
+	}
+	if req.AssigneeID != nil {
+		if *req.AssigneeID == 0 {
+			tpl.AssigneeID = nil
+		} else {
+			if _, _, err := access.Require(h.DB, tpl.ProjectID, *req.AssigneeID, database.ProjectRoleMember); err != nil {
+				if errors.Is(err, access.ErrNotFound) || errors.Is(err, access.ErrForbidden) {
+					utils.Error(w, http.StatusBadRequest, "validation", "assignee must be a project member")
+					return false
+				}
+				utils.Error(w, http.StatusInternalServerError, "db_access", "failed to check assignee")
+				return false
+			}
+			tpl.AssigneeID = req.AssigneeID
+		}
+	}
+	if req.Priority != nil {
+		p := database.TaskPriority(strings.ToLower(strings.TrimSpace(*req.Priority)))
+		switch p {
+		case "":
+			tpl.Priority = nil
+		case database.TaskPriorityLow, database.TaskPriorityMedium, database.TaskPriorityHigh, database.TaskPriorityUrgent:
+			tpl.Priority = &p
+		default:
+			utils.Error(w, http.StatusBadRequest, "validation", "invalid priority")
+			return false
+		}
+	}
+	if req.EstimatePoints != nil {
+		switch e := *req.EstimatePoints; {
+		case e == 0:
+			tpl.EstimatePoints = nil
+		case e < 1 || e > 1000:
+			utils.Error(w, http.StatusBadRequest, "validation", "estimate_points must be between 1 and 1000")
+			return false
+		default:
+			tpl.EstimatePoints = &e
+		}
+	}
+	if req.DueInDays != nil {
+		switch d := *req.DueInDays; {
+		case d == -1:
+			tpl.DueInDays = nil
+		case d < 0 || d > 365:
+			utils.Error(w, http.StatusBadRequest, "validation", "due_in_days must be between 0 and 365")
+			return false
+		default:
+			tpl.DueInDays = &d
+		}
+	}
+	if req.LabelIDs != nil {
+		ids := *req.LabelIDs
+		if len(ids) > 0 {
+			var n int64
+			if err := h.DB.Model(&database.Label{}).
+				Where("id IN ? AND project_id = ?", ids, tpl.ProjectID).
+				Count(&n).Error; err != nil {
+				utils.Error(w, http.StatusInternalServerError, "db", "failed to load labels")
+				return false
+			}
+			if int(n) != len(ids) {
+				utils.Error(w, http.StatusBadRequest, "validation", "labels must belong to this project")
+				return false
+			}
+		}
+		tpl.LabelIDs = ids
+	}
+
+	reschedule := tpl.ID == 0
+	if req.Recurrence != nil {
+		tpl.Recurrence = strings.TrimSpace(*req.Recurrence)
+		reschedule = true
+	}
+	if req.Timezone != nil {
+		tpl.Timezone = strings.TrimSpace(*req.Timezone)
+		reschedule = true
+	}
+	if req.Enabled != nil {
+		if *req.Enabled && !tpl.Enabled {
+			reschedule = true
+		}
+		tpl.Enabled = *req.Enabled
+	}
+	if reschedule {
+		next, err := templates.NextRun(tpl.Recurrence, tpl.Timezone, time.Now())
+		if err != nil {
+			utils.Error(w, http.StatusBadRequest, "validation", err.Error())
+			return false
+		}
+		tpl.NextRunAt = next
+	}
+	return true
+}
+
+func (h *Handler) loadTemplate(w http.ResponseWriter, r *http.Request, p database.Project) (database.TaskTemplate, bool) {
+	var tpl database.TaskTemplate
+	tid, err := strconv.ParseUint(chi.URLParam(r, "templateID"), 10, 64)
+	if err != nil || tid == 0 {
+		utils.Error(w, http.StatusBadRequest, "bad_id", "invalid template id")
+		return tpl, false
+	}
+	if err := h.DB.Where("id = ? AND project_id = ?", tid, p.ID).First(&tpl).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.Error(w, http.StatusNotFound, "not_found", "template not found")
+			return tpl, false
+		}
+		utils.Error(w, http.StatusInternalServerError, "db", "failed to load template")
+		return tpl, false
+	}
+	return tpl, true
+}
@@ -12,6 +12,9 @@ func toResp(p database.Project, hasChildren bool) ProjectResponse {
 		UpdatedAt:   p.UpdatedAt,
 		ParentID:    p.ParentID,
 		HasChildren: hasChildren,
+
+		BlockedPolicy:  p.BlockedPolicy,
+		SyncIssueState: p.SyncIssueState,
 	}
 }
 
@@ -0,0 +1,125 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Hub keeps one LISTEN connection per process and fans notifications out to
+// the SSE subscribers of each project.
+type Hub struct {
+	dsn string
+
+	mu     sync.Mutex
+	subs   map[uint]map[chan Event]struct{}
+	closed bool
+}
+
+func NewHub(dsn string) *Hub {
+	return &Hub{dsn: dsn, subs: make(map[uint]map[chan Event]struct{})}
+}
+
+// Subscribe registers for a project's events. The channel is closed when the
+// hub shuts down; call the returned func to unsubscribe.
+func (h *Hub) Subscribe(projectID uint) (<-chan Event, func()) {
+	ch := make(chan Event, 16)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	if h.subs[projectID] == nil {
+		h.subs[projectID] = make(map[chan Event]struct{})
+	}
+	h.subs[projectID][ch] = struct{}{}
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subs[projectID][ch]; !ok {
+			return
+		}
+		delete(h.subs[projectID], ch)
+		if len(h.subs[projectID]) == 0 {
+			delete(h.subs, projectID)
+		}
+		close(ch)
+	}
+}
+
+// Run listens until ctx is cancelled, reconnecting with backoff.
+func (h *Hub) Run(ctx context.Context) {
+	defer h.shutdown()
+
+	backoff := time.Second
+	for {
+		err := h.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("realtime listener: %v (retrying in %s)", err, backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+func (h *Hub) listen(ctx context.Context) error {
+	conn, err := pgx.Connect(ctx, h.dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+channel); err != nil {
+		return err
+	}
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var ev Event
+		if err := json.Unmarshal([]byte(n.Payload), &ev); err != nil {
+			log.Printf("realtime: bad payload: %v", err)
+			continue
+		}
+		h.dispatch(ev)
+	}
+}
+
+func (h *Hub) dispatch(ev Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs[ev.ProjectID] {
+		// Slow clients miss events rather than stall everyone else
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+func (h *Hub) shutdown() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for pid, set := range h.subs {
+		for ch := range set {
+			close(ch)
+		}
+		delete(h.subs, pid)
+	}
+}
@@ -0,0 +1,57 @@
+package realtime
+
+import (
+	"encoding/json"
+
+	"github.com/AJMerr/hydianflow/internal/database"
+	"gorm.io/gorm"
+)
+
+// Postgres channel carrying board events between API replicas
+const channel = "hf_task_events"
+
+const (
+	TaskCreated = "task.created"
+	TaskUpdated = "task.updated"
+	TaskDeleted = "task.deleted"
+)
+
+const (
+	SourceUser      = "user"
+	SourceWebhook   = "webhook"
+	SourceScheduler = "scheduler"
+)
+
+// Event is deliberately small; clients refetch the task for full details.
+type Event struct {
+	Type      string `json:"type"`
+	TaskID    uint   `json:"task_id"`
+	ProjectID uint   `json:"project_id"`
+	Status    string `json:"status,omitempty"`
+	Source    string `json:"source"`
+}
+
+// Publish queues a NOTIFY on tx. When tx is a transaction the event is only
+// delivered once it commits.
+func Publish(tx *gorm.DB, ev Event) error {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	return tx.Exec("SELECT pg_notify(?, ?)", channel, string(b)).Error
+}
+
+// PublishTask publishes an event for a task. Personal tasks have no project
+// board, so nothing is sent for them.
+func PublishTask(tx *gorm.DB, typ, source string, t database.Task) error {
+	if t.ProjectID == nil {
+		return nil
+	}
+	return Publish(tx, Event{
+		Type:      typ,
+		TaskID:    t.ID,
+		ProjectID: *t.ProjectID,
+		Status:    string(t.Status),
+		Source:    source,
+	})
+}
@@ -0,0 +1,201 @@
+package tasks
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/githubhttp"
+	"github.com/AJMerr/hydianflow/internal/history"
+	"github.com/AJMerr/hydianflow/internal/realtime"
+	"github.com/AJMerr/hydianflow/internal/utils"
+	"github.com/AJMerr/hydianflow/internal/workflow"
+	"gorm.io/gorm"
+)
+
+// DefaultBranchTemplate names branches created from tasks. {id}, {slug}
+// (from the title) and {tag} are substituted.
+const DefaultBranchTemplate = "feat/{id}-{slug}"
+
+const maxSlugLen = 40
+
+var (
+	slugDrop  = regexp.MustCompile(`[^a-z0-9]+`)
+	branchRe  = regexp.MustCompile(`^[A-Za-z0-9._/-]+$`)
+	errBranch = errors.New("invalid branch name")
+)
+
+// TaskBranchRequest optionally overrides the generated branch name and the
+// branch it is cut from (the repository's default branch otherwise).
+type TaskBranchRequest struct {
+	Name *string `json:"name,omitempty"`
+	Base *string `json:"base,omitempty"`
+}
+
+type TaskBranchResponse struct {
+	Branch string       `json:"branch"`
+	SHA    string       `json:"sha"`
+	Task   TaskResponse `json:"task"`
+}
+
+func slugify(s string) string {
+	s = strings.Trim(slugDrop.ReplaceAllString(strings.ToLower(s), "-"), "-")
+	if len(s) > maxSlugLen {
+		s = strings.TrimRight(s[:maxSlugLen], "-")
+	}
+	if s == "" {
+		s = "task"
+	}
+	return s
+}
+
+// branchName fills in tmpl for t.
+func branchName(tmpl string, t database.Task) string {
+	if tmpl == "" {
+		tmpl = DefaultBranchTemplate
+	}
+	tag := "task"
+	if t.Tag != nil {
+		tag = strings.ReplaceAll(*t.Tag, "_", "-")
+	}
+	return strings.NewReplacer(
+		"{id}", strconv.FormatUint(uint64(t.ID), 10),
+		"{slug}", slugify(t.Title),
+		"{tag}", tag,
+	).Replace(tmpl)
+}
+
+// validBranch applies the subset of git's ref rules that matter for names
+// people type.
+func validBranch(s string) bool {
+	return branchRe.MatchString(s) &&
+		!strings.Contains(s, "..") && !strings.Contains(s, "//") &&
+		!strings.HasPrefix(s, "/") && !strings.HasSuffix(s, "/") &&
+		!strings.HasPrefix(s, "-") && !strings.HasSuffix(s, ".lock") && !strings.HasSuffix(s, ".")
+}
+
+// POST /api/v1/tasks/{id}/branch
+//
+// Creates the task's branch on GitHub with the caller's token, records it as
+// the branch hint and starts the task if it hasn't been started yet and the
+// workflow has a doing status to move it to.
+func (h *Handler) CreateBranch(w http.ResponseWriter, r *http.Request) {
+	uid, ok := mustUserID(r)
+	if !ok {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "login required")
+		return
+	}
+
+	t, ok := h.loadTask(w, r, uid, database.ProjectRoleMember)
+	if !ok {
+		return
+	}
+	before := t
+
+	var req TaskBranchRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.Error(w, http.StatusBadRequest, "bad_json", "invalid JSON body")
+		return
+	}
+	if t.RepoName == nil || *t.RepoName == "" {
+		utils.Error(w, http.StatusBadRequest, "validation", "task has no repo_full_name")
+		return
+	}
+
+	name := branchName(h.BranchTemplate, t)
+	if req.Name != nil {
+		name = strings.TrimSpace(*req.Name)
+	}
+	base := ""
+	if req.Base != nil {
+		base = strings.TrimSpace(*req.Base)
+	}
+	if !validBranch(name) || (base != "" && !validBranch(base)) {
+		utils.Error(w, http.StatusBadRequest, "validation", errBranch.Error())
+		return
+	}
+
+	// Decide the move, and refuse a blocked task, before touching GitHub
+	wf, err := workflow.ForProject(h.DB, t.ProjectID)
+	if err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_workflow", "could not load project workflow")
+		return
+	}
+	if cat, _ := wf.Category(string(t.Status)); cat == database.StatusCategoryTodo {
+		// Custom workflows need not have a doing column
+		if doing := wf.First(database.StatusCategoryDoing); doing != "" {
+			t.Status = database.TaskStatus(doing)
+			wf.Stamp(&t, time.Now().UTC())
+		}
+	}
+	warning, err := checkStart(h.DB, wf, before, t)
+	if err != nil {
+		if !writeBlocked(w, err) {
+			utils.Error(w, http.StatusInternalServerError, "db_get", "could not check task dependencies")
+		}
+		return
+	}
+	var warnings []string
+	if warning != "" {
+		warnings = append(warnings, warning)
+	}
+
+	if h.GH == nil {
+		utils.Error(w, http.StatusServiceUnavailable, "github_unavailable", "GitHub integration is not configured")
+		return
+	}
+	ref, err := h.GH.CreateBranch(r.Context(), uid, *t.RepoName, name, base)
+	if err != nil {
+		githubhttp.WriteError(w, err, func(string) (int, string) {
+			return http.StatusConflict, "branch_exists"
+		})
+		return
+	}
+	t.BranchHint = &name
+
+	if err := h.DB.Transaction(func(tx *gorm.DB) error {
+		// Only the columns set here: the task may have changed while GitHub
+		// was busy
+		updates := map[string]any{
+			"branch_hint": t.BranchHint,
+			"updated_at":  time.Now().UTC(),
+		}
+		if t.Status != before.Status {
+			updates["status"] = t.Status
+			updates["started_at"] = t.StartedAt
+		}
+		if err := tx.Model(&database.Task{}).Where("id = ?", t.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+		if err := history.Record(tx, t.ID, history.UserActor(uid), history.Diff(before, t)...); err != nil {
+			return err
+		}
+		if err := tx.First(&t, t.ID).Error; err != nil {
+			return err
+		}
+		return realtime.PublishTask(tx, realtime.TaskUpdated, realtime.SourceUser, t)
+	}); err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_update", "branch created but the task could not be updated")
+		return
+	}
+
+	out, err := toResps(h.DB, []database.Task{t})
+	if err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_get", "could not load task progress")
+		return
+	}
+	out[0].Warnings = warnings
+	utils.JSON(w, http.StatusCreated, TaskBranchResponse{
+		Branch: name,
+		SHA:    ref.GetObject().GetSHA(),
+		Task:   out[0],
+	})
+}
@@ -0,0 +1,88 @@
+package tasks
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AJMerr/hydianflow/internal/database"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Fix Login Bug!", "fix-login-bug"},
+		{"  --Hello__World--  ", "hello-world"},
+		{"v2.0 release", "v2-0-release"},
+		{"Ünïcode café", "n-code-caf"},
+		{"", "task"},
+		{"!!!", "task"},
+		{strings.Repeat("a", 50), strings.Repeat("a", maxSlugLen)},
+		// Cut at the limit, then the dangling separator is dropped
+		{strings.Repeat("a", maxSlugLen-1) + " b", strings.Repeat("a", maxSlugLen-1)},
+	}
+	for _, tt := range tests {
+		if got := slugify(tt.in); got != tt.want {
+			t.Errorf("slugify(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestValidBranch(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"main", true},
+		{"feat/12-add-search", true},
+		{"release/1.2", true},
+		{"user_name/fix-it", true},
+		{"", false},
+		{"has space", false},
+		{"a~1", false},
+		{"a..b", false},
+		{"a//b", false},
+		{"/lead", false},
+		{"trail/", false},
+		{"-dash", false},
+		{"ref.lock", false},
+		{"dot.", false},
+	}
+	for _, tt := range tests {
+		if got := validBranch(tt.name); got != tt.want {
+			t.Errorf("validBranch(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBranchName(t *testing.T) {
+	tag := "feature_request"
+	tests := []struct {
+		name string
+		tmpl string
+		task database.Task
+		want string
+	}{
+		{"default template", "", newTask(12, "Add search", nil), "feat/12-add-search"},
+		{"tag", "{tag}/{id}-{slug}", newTask(7, "Crash on save", &tag), "feature-request/7-crash-on-save"},
+		{"no tag", "{tag}/{id}", newTask(7, "", nil), "task/7"},
+		{"literal text kept", "wip-{id}", newTask(3, "x", nil), "wip-3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := branchName(tt.tmpl, tt.task)
+			if got != tt.want {
+				t.Errorf("branchName = %q, want %q", got, tt.want)
+			}
+			if !validBranch(got) {
+				t.Errorf("branchName produced invalid branch %q", got)
+			}
+		})
+	}
+}
+
+func newTask(id uint, title string, tag *string) database.Task {
+	t := database.Task{Title: title, Tag: tag}
+	t.ID = id
+	return t
+}
@@ -0,0 +1,275 @@
+package tasks
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/AJMerr/hydianflow/internal/access"
+	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/history"
+	"github.com/AJMerr/hydianflow/internal/realtime"
+	"github.com/AJMerr/hydianflow/internal/utils"
+	"github.com/AJMerr/hydianflow/internal/workflow"
+	"gorm.io/gorm"
+)
+
+const maxBulkIDs = 200
+
+const (
+	BulkSetStatus   = "set_status"
+	BulkSetAssignee = "set_assignee"
+	BulkSetTag      = "set_tag"
+	BulkMoveProject = "move_project"
+	BulkDelete      = "delete"
+)
+
+// TaskBulkRequest applies one operation to many tasks. Only the field that
+// belongs to the operation is read; a missing assignee_id or tag clears it.
+// Setting a tag fails for project tasks, which use labels.
+type TaskBulkRequest struct {
+	IDs        []uint  `json:"ids"`
+	Op         string  `json:"op"`
+	Status     *string `json:"status,omitempty"`
+	AssigneeID *uint   `json:"assignee_id,omitempty"`
+	Tag        *string `json:"tag,omitempty"`
+	ProjectID  *uint   `json:"project_id,omitempty"`
+}
+
+type BulkItemError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type BulkItemResult struct {
+	ID      uint           `json:"id"`
+	OK      bool           `json:"ok"`
+	Task    *TaskResponse  `json:"task,omitempty"`
+	Warning string         `json:"warning,omitempty"`
+	Error   *BulkItemError `json:"error,omitempty"`
+}
+
+// POST /api/v1/tasks/bulk
+//
+// Every item is checked and applied in one transaction. Items the caller may
+// not touch, or that fail validation, are reported and skipped; the rest are
+// committed together.
+func (h *Handler) Bulk(w http.ResponseWriter, r *http.Request) {
+	uid, ok := mustUserID(r)
+	if !ok {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "login required")
+		return
+	}
+
+	var req TaskBulkRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		utils.Error(w, http.StatusBadRequest, "bad_json", "invalid JSON body")
+		return
+	}
+
+	ids := make([]uint, 0, len(req.IDs))
+	seen := make(map[uint]struct{}, len(req.IDs))
+	for _, id := range req.IDs {
+		if _, dup := seen[id]; dup || id == 0 {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		utils.Error(w, http.StatusBadRequest, "validation", "ids is required")
+		return
+	}
+	if len(ids) > maxBulkIDs {
+		utils.Error(w, http.StatusBadRequest, "validation", "too many ids")
+		return
+	}
+
+	switch req.Op {
+	case BulkSetStatus:
+		if req.Status == nil {
+			utils.Error(w, http.StatusBadRequest, "validation", "status is required")
+			return
+		}
+	case BulkSetTag:
+		if req.Tag != nil {
+			if _, ok := normalTag(*req.Tag); !ok {
+				utils.Error(w, http.StatusBadRequest, "validation", "invalid tag")
+				return
+			}
+		}
+	case BulkMoveProject:
+		if req.ProjectID == nil {
+			utils.Error(w, http.StatusBadRequest, "validation", "project_id is required")
+			return
+		}
+		if !h.canUseProject(w, *req.ProjectID, uid) {
+			return
+		}
+	case BulkSetAssignee, BulkDelete:
+	default:
+		utils.Error(w, http.StatusBadRequest, "validation", "invalid op")
+		return
+	}
+
+	// Every op but delete is the matching single-field update, so items get
+	// the same validation and side effects as PATCH /tasks/{id}
+	var upd TaskUpdateRequest
+	switch req.Op {
+	case BulkSetStatus:
+		upd.Status = req.Status
+	case BulkSetAssignee:
+		var id uint
+		if req.AssigneeID != nil {
+			id = *req.AssigneeID
+		}
+		upd.AssigneeID = &id
+	case BulkSetTag:
+		var tag string
+		if req.Tag != nil {
+			tag = *req.Tag
+		}
+		upd.Tag = &tag
+	case BulkMoveProject:
+		upd.ProjectID = req.ProjectID
+	}
+
+	results := make([]BulkItemResult, len(ids))
+	now := time.Now().UTC()
+	// Status changes to push to linked GitHub issues once committed
+	type change struct {
+		i             int
+		before, after database.Task
+	}
+	var changed []change
+	// Updated tasks and their index in results
+	var updated []database.Task
+	var updatedAt []int
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		var rows []database.Task
+		if err := tx.Where("id IN ?", ids).Find(&rows).Error; err != nil {
+			return err
+		}
+		byID := make(map[uint]database.Task, len(rows))
+		for _, t := range rows {
+			byID[t.ID] = t
+		}
+
+		workflows := workflow.NewCache(tx)
+		actor := history.UserActor(uid)
+
+		for i, id := range ids {
+			res := &results[i]
+			res.ID = id
+			fail := func(code, msg string) {
+				res.Error = &BulkItemError{Code: code, Message: msg}
+			}
+
+			t, ok := byID[id]
+			if !ok {
+				fail("not_found", "task not found")
+				continue
+			}
+			role, err := access.TaskRole(tx, t, uid)
+			if errors.Is(err, access.ErrNotFound) {
+				fail("not_found", "task not found")
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			min := database.ProjectRoleMember
+			if req.Op == BulkDelete && t.CreatorID != uid {
+				min = database.ProjectRoleAdmin
+			}
+			if !access.AtLeast(role, min) {
+				fail("forbidden", "insufficient project role")
+				continue
+			}
+
+			if req.Op == BulkDelete {
+				if err := tx.Delete(&t).Error; err != nil {
+					return err
+				}
+				if err := detachChildren(tx, t.ID); err != nil {
+					return err
+				}
+				if err := history.Record(tx, t.ID, actor, history.Change{
+					Field:    history.FieldDeleted,
+					OldValue: history.Str(t.Title),
+				}); err != nil {
+					return err
+				}
+				if err := realtime.PublishTask(tx, realtime.TaskDeleted, realtime.SourceUser, t); err != nil {
+					return err
+				}
+				res.OK = true
+				continue
+			}
+
+			c, err := prepareUpdate(tx, workflows, uid, t, upd, now)
+			var te taskError
+			switch {
+			case errors.As(err, &te):
+				fail(te.code, te.message)
+				continue
+			case errors.As(err, new(blockedError)):
+				fail("blocked", err.Error())
+				continue
+			case err != nil:
+				return err
+			}
+			res.Warning = c.warning
+			if err := c.save(tx, uid); err != nil {
+				return err
+			}
+			res.OK = true
+			updated = append(updated, c.after)
+			updatedAt = append(updatedAt, i)
+			if c.after.Status != c.before.Status {
+				changed = append(changed, change{i: i, before: c.before, after: c.after})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_update", "could not apply bulk operation")
+		return
+	}
+	resps, err := toResps(h.DB, updated)
+	if err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_get", "could not load task progress")
+		return
+	}
+	for j, i := range updatedAt {
+		results[i].Task = &resps[j]
+	}
+	for _, c := range changed {
+		if warning := h.syncIssue(r.Context(), uid, c.before, c.after); warning != "" && results[c.i].Warning == "" {
+			results[c.i].Warning = warning
+		}
+	}
+
+	failed := 0
+	for _, res := range results {
+		if !res.OK {
+			failed++
+		}
+	}
+	type bulkResp struct {
+		Op        string           `json:"op"`
+		Succeeded int              `json:"succeeded"`
+		Failed    int              `json:"failed"`
+		Items     []BulkItemResult `json:"items"`
+	}
+	utils.JSON(w, http.StatusOK, bulkResp{
+		Op:        req.Op,
+		Succeeded: len(results) - failed,
+		Failed:    failed,
+		Items:     results,
+	})
+}
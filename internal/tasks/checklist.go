@@ -0,0 +1,226 @@
+package tasks
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/realtime"
+	"github.com/AJMerr/hydianflow/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+const (
+	maxChecklistItemLen = 500
+	maxChecklistItems   = 100
+)
+
+type ChecklistItemRequest struct {
+	Body     *string  `json:"body,omitempty"`
+	Done     *bool    `json:"done,omitempty"`
+	Position *float64 `json:"position,omitempty"`
+}
+
+// GET /api/v1/tasks/{id}/checklist
+func (h *Handler) ListChecklist(w http.ResponseWriter, r *http.Request) {
+	uid, ok := mustUserID(r)
+	if !ok {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "login required")
+		return
+	}
+
+	t, ok := h.loadTask(w, r, uid, database.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+
+	var items []database.TaskChecklistItem
+	if err := h.DB.Where("task_id = ?", t.ID).
+		Order("position ASC, id ASC").
+		Find(&items).Error; err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_list", "could not load checklist")
+		return
+	}
+	utils.JSON(w, http.StatusOK, items)
+}
+
+// POST /api/v1/tasks/{id}/checklist
+func (h *Handler) CreateChecklistItem(w http.ResponseWriter, r *http.Request) {
+	uid, ok := mustUserID(r)
+	if !ok {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "login required")
+		return
+	}
+
+	t, ok := h.loadTask(w, r, uid, database.ProjectRoleMember)
+	if !ok {
+		return
+	}
+
+	req, ok := decodeChecklistItem(w, r)
+	if !ok {
+		return
+	}
+	if req.Body == nil {
+		utils.Error(w, http.StatusBadRequest, "validation", "body is required")
+		return
+	}
+
+	item := database.TaskChecklistItem{TaskID: t.ID, Body: *req.Body}
+	if req.Done != nil {
+		item.Done = *req.Done
+	}
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		var n int64
+		if err := tx.Model(&database.TaskChecklistItem{}).Where("task_id = ?", t.ID).Count(&n).Error; err != nil {
+			return err
+		}
+		if n >= maxChecklistItems {
+			return errChecklistFull
+		}
+		if req.Position != nil {
+			item.Position = *req.Position
+		} else {
+			var maxPos float64
+			if err := tx.Model(&database.TaskChecklistItem{}).
+				Where("task_id = ?", t.ID).
+				Select("COALESCE(MAX(position), 0)").
+				Scan(&maxPos).Error; err != nil {
+				return err
+			}
+			item.Position = maxPos + positionStep
+		}
+		if err := tx.Create(&item).Error; err != nil {
+			return err
+		}
+		return realtime.PublishTask(tx, realtime.TaskUpdated, realtime.SourceUser, t)
+	})
+	if errors.Is(err, errChecklistFull) {
+		utils.Error(w, http.StatusBadRequest, "validation", "checklist is full")
+		return
+	}
+	if err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_create", "could not add checklist item")
+		return
+	}
+	utils.JSON(w, http.StatusCreated, item)
+}
+
+// PATCH /api/v1/tasks/{id}/checklist/{itemID}
+func (h *Handler) UpdateChecklistItem(w http.ResponseWriter, r *http.Request) {
+	uid, ok := mustUserID(r)
+	if !ok {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "login required")
+		return
+	}
+
+	t, ok := h.loadTask(w, r, uid, database.ProjectRoleMember)
+	if !ok {
+		return
+	}
+	item, ok := h.loadChecklistItem(w, r, t)
+	if !ok {
+		return
+	}
+
+	req, ok := decodeChecklistItem(w, r)
+	if !ok {
+		return
+	}
+	if req.Body != nil {
+		item.Body = *req.Body
+	}
+	if req.Done != nil {
+		item.Done = *req.Done
+	}
+	if req.Position != nil {
+		item.Position = *req.Position
+	}
+
+	if err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&item).Error; err != nil {
+			return err
+		}
+		return realtime.PublishTask(tx, realtime.TaskUpdated, realtime.SourceUser, t)
+	}); err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_update", "could not update checklist item")
+		return
+	}
+	utils.JSON(w, http.StatusOK, item)
+}
+
+// DELETE /api/v1/tasks/{id}/checklist/{itemID}
+func (h *Handler) DeleteChecklistItem(w http.ResponseWriter, r *http.Request) {
+	uid, ok := mustUserID(r)
+	if !ok {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "login required")
+		return
+	}
+
+	t, ok := h.loadTask(w, r, uid, database.ProjectRoleMember)
+	if !ok {
+		return
+	}
+	item, ok := h.loadChecklistItem(w, r, t)
+	if !ok {
+		return
+	}
+
+	if err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&item).Error; err != nil {
+			return err
+		}
+		return realtime.PublishTask(tx, realtime.TaskUpdated, realtime.SourceUser, t)
+	}); err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_delete", "could not delete checklist item")
+		return
+	}
+	utils.JSON(w, http.StatusOK, map[string]string{"ok": "true"})
+}
+
+var errChecklistFull = errors.New("checklist is full")
+
+func decodeChecklistItem(w http.ResponseWriter, r *http.Request) (ChecklistItemRequest, bool) {
+	var req ChecklistItemRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		utils.Error(w, http.StatusBadRequest, "bad_json", "invalid JSON body")
+		return req, false
+	}
+	if req.Body != nil {
+		body := strings.TrimSpace(*req.Body)
+		if body == "" {
+			utils.Error(w, http.StatusBadRequest, "validation", "body must not be empty")
+			return req, false
+		}
+		if len(body) > maxChecklistItemLen {
+			utils.Error(w, http.StatusBadRequest, "validation", "checklist item is too long")
+			return req, false
+		}
+		req.Body = &body
+	}
+	return req, true
+}
+
+func (h *Handler) loadChecklistItem(w http.ResponseWriter, r *http.Request, t database.Task) (database.TaskChecklistItem, bool) {
+	var item database.TaskChecklistItem
+	iid, err := strconv.ParseUint(chi.URLParam(r, "itemID"), 10, 64)
+	if err != nil || iid == 0 {
+		utils.Error(w, http.StatusBadRequest, "bad_id", "invalid checklist item id")
+		return item, false
+	}
+	if err := h.DB.Where("id = ? AND task_id = ?", iid, t.ID).First(&item).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.Error(w, http.StatusNotFound, "not_found", "checklist item not found")
+			return item, false
+		}
+		utils.Error(w, http.StatusInternalServerError, "db_get", "could not load checklist item")
+		return item, false
+	}
+	return item, true
+}
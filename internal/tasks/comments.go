@@ -0,0 +1,341 @@
+package tasks
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AJMerr/hydianflow/internal/access"
+	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const maxCommentLen = 10000
+
+type CommentRequest struct {
+	Body string `json:"body"`
+}
+
+type CommentUser struct {
+	ID          uint   `json:"id"`
+	GithubLogin string `json:"github_login"`
+}
+
+type CommentResponse struct {
+	ID          uint          `json:"id"`
+	TaskID      uint          `json:"task_id"`
+	AuthorID    uint          `json:"author_id"`
+	AuthorLogin string        `json:"author_login"`
+	Body        string        `json:"body"`
+	Mentions    []CommentUser `json:"mentions"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// GitHub logins: alphanumerics and single hyphens, up to 39 chars
+var mentionRe = regexp.MustCompile(`(?:^|[^\w@/])@([A-Za-z0-9](?:[A-Za-z0-9-]{0,38}))`)
+
+// GET /api/v1/tasks/{id}/comments
+func (h *Handler) ListComments(w http.ResponseWriter, r *http.Request) {
+	uid, ok := mustUserID(r)
+	if !ok {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "login required")
+		return
+	}
+
+	t, ok := h.loadTask(w, r, uid, database.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+
+	limit := parseLimit(r, 50, 200)
+	cursor := parseCursor(r)
+
+	q := h.DB.Where("task_id = ?", t.ID).Order("id ASC").Limit(limit)
+	if cursor > 0 {
+		q = q.Where("id > ?", cursor)
+	}
+	var rows []database.TaskComment
+	if err := q.Find(&rows).Error; err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_list", "could not load comments")
+		return
+	}
+
+	items, err := h.commentResps(rows)
+	if err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_list", "could not load comments")
+		return
+	}
+
+	nextCursor := uint(0)
+	if len(rows) == limit {
+		nextCursor = rows[len(rows)-1].ID
+	}
+
+	type listResp struct {
+		Items      []CommentResponse `json:"items"`
+		NextCursor uint              `json:"next_cursor"`
+	}
+	utils.JSON(w, http.StatusOK, listResp{Items: items, NextCursor: nextCursor})
+}
+
+// POST /api/v1/tasks/{id}/comments
+func (h *Handler) CreateComment(w http.ResponseWriter, r *http.Request) {
+	uid, ok := mustUserID(r)
+	if !ok {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "login required")
+		return
+	}
+
+	t, ok := h.loadTask(w, r, uid, database.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+
+	body, ok := decodeComment(w, r)
+	if !ok {
+		return
+	}
+
+	c := database.TaskComment{TaskID: t.ID, AuthorID: uid, Body: body}
+	if err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&c).Error; err != nil {
+			return err
+		}
+		return saveMentions(tx, c.ID, body)
+	}); err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_create", "could not create comment")
+		return
+	}
+
+	h.writeComment(w, http.StatusCreated, c)
+}
+
+// PATCH /api/v1/tasks/{id}/comments/{commentID}
+func (h *Handler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	uid, ok := mustUserID(r)
+	if !ok {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "login required")
+		return
+	}
+
+	t, ok := h.loadTask(w, r, uid, database.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+	c, ok := h.loadComment(w, r, t)
+	if !ok {
+		return
+	}
+	if c.AuthorID != uid {
+		utils.Error(w, http.StatusForbidden, "forbidden", "only the author can edit a comment")
+		return
+	}
+
+	body, ok := decodeComment(w, r)
+	if !ok {
+		return
+	}
+
+	c.Body = body
+	if err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&c).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("comment_id = ?", c.ID).Delete(&database.TaskCommentMention{}).Error; err != nil {
+			return err
+		}
+		return saveMentions(tx, c.ID, body)
+	}); err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_update", "could not update comment")
+		return
+	}
+
+	h.writeComment(w, http.StatusOK, c)
+}
+
+// DELETE /api/v1/tasks/{id}/comments/{commentID}
+func (h *Handler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	uid, ok := mustUserID(r)
+	if !ok {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "login required")
+		return
+	}
+
+	t, ok := h.loadTask(w, r, uid, database.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+	c, ok := h.loadComment(w, r, t)
+	if !ok {
+		return
+	}
+	// Authors delete their own comments; admins may moderate
+	if c.AuthorID != uid {
+		if _, err := access.RequireTask(h.DB, t, uid, database.ProjectRoleAdmin); err != nil {
+			utils.Error(w, http.StatusForbidden, "forbidden", "only the author can delete a comment")
+			return
+		}
+	}
+
+	if err := h.DB.Delete(&c).Error; err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_delete", "could not delete comment")
+		return
+	}
+	utils.JSON(w, http.StatusOK, map[string]string{"ok": "true"})
+}
+
+func decodeComment(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var req CommentRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		utils.Error(w, http.StatusBadRequest, "bad_json", "invalid JSON body")
+		return "", false
+	}
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		utils.Error(w, http.StatusBadRequest, "validation", "body is required")
+		return "", false
+	}
+	if len(body) > maxCommentLen {
+		utils.Error(w, http.StatusBadRequest, "validation", "comment is too long")
+		return "", false
+	}
+	return body, true
+}
+
+func (h *Handler) loadComment(w http.ResponseWriter, r *http.Request, t database.Task) (database.TaskComment, bool) {
+	var c database.TaskComment
+	cid, err := strconv.ParseUint(chi.URLParam(r, "commentID"), 10, 64)
+	if err != nil || cid == 0 {
+		utils.Error(w, http.StatusBadRequest, "bad_id", "invalid comment id")
+		return c, false
+	}
+	if err := h.DB.Where("id = ? AND task_id = ?", cid, t.ID).First(&c).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.Error(w, http.StatusNotFound, "not_found", "comment not found")
+			return c, false
+		}
+		utils.Error(w, http.StatusInternalServerError, "db_get", "could not load comment")
+		return c, false
+	}
+	return c, true
+}
+
+func (h *Handler) writeComment(w http.ResponseWriter, status int, c database.TaskComment) {
+	items, err := h.commentResps([]database.TaskComment{c})
+	if err != nil || len(items) != 1 {
+		utils.Error(w, http.StatusInternalServerError, "db_get", "could not load comment")
+		return
+	}
+	utils.JSON(w, status, items[0])
+}
+
+// parseMentions returns the distinct @logins referenced in a comment body.
+func parseMentions(body string) []string {
+	seen := map[string]struct{}{}
+	out := make([]string, 0, 2)
+	for _, m := range mentionRe.FindAllStringSubmatch(body, -1) {
+		login := strings.ToLower(strings.TrimRight(m[1], "-"))
+		if _, ok := seen[login]; ok || login == "" {
+			continue
+		}
+		seen[login] = struct{}{}
+		out = append(out, login)
+	}
+	return out
+}
+
+// saveMentions links the comment to every known user it mentions. Unknown
+// logins are ignored.
+func saveMentions(tx *gorm.DB, commentID uint, body string) error {
+	logins := parseMentions(body)
+	if len(logins) == 0 {
+		return nil
+	}
+	var ids []uint
+	if err := tx.Model(&database.User{}).
+		Where("LOWER(github_login) IN ?", logins).
+		Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	rows := make([]database.TaskCommentMention, len(ids))
+	for i, id := range ids {
+		rows[i] = database.TaskCommentMention{CommentID: commentID, UserID: id}
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error
+}
+
+// commentResps loads authors and mentions for a page of comments.
+func (h *Handler) commentResps(rows []database.TaskComment) ([]CommentResponse, error) {
+	out := make([]CommentResponse, len(rows))
+	if len(rows) == 0 {
+		return out, nil
+	}
+
+	commentIDs := make([]uint, len(rows))
+	authorIDs := make([]uint, 0, len(rows))
+	for i, c := range rows {
+		commentIDs[i] = c.ID
+		authorIDs = append(authorIDs, c.AuthorID)
+	}
+
+	var authors []CommentUser
+	if err := h.DB.Model(&database.User{}).
+		Select("id, github_login").
+		Where("id IN ?", authorIDs).
+		Scan(&authors).Error; err != nil {
+		return nil, err
+	}
+	logins := make(map[uint]string, len(authors))
+	for _, a := range authors {
+		logins[a.ID] = a.GithubLogin
+	}
+
+	type mentionRow struct {
+		CommentID   uint
+		ID          uint
+		GithubLogin string
+	}
+	var mentions []mentionRow
+	if err := h.DB.Table("task_comment_mentions m").
+		Select("m.comment_id, u.id, u.github_login").
+		Joins("JOIN users u ON u.id = m.user_id").
+		Where("m.comment_id IN ?", commentIDs).
+		Scan(&mentions).Error; err != nil {
+		return nil, err
+	}
+	byComment := make(map[uint][]CommentUser, len(rows))
+	for _, m := range mentions {
+		byComment[m.CommentID] = append(byComment[m.CommentID], CommentUser{ID: m.ID, GithubLogin: m.GithubLogin})
+	}
+
+	for i, c := range rows {
+		ms := byComment[c.ID]
+		if ms == nil {
+			ms = []CommentUser{}
+		}
+		out[i] = CommentResponse{
+			ID:          c.ID,
+			TaskID:      c.TaskID,
+			AuthorID:    c.AuthorID,
+			AuthorLogin: logins[c.AuthorID],
+			Body:        c.Body,
+			Mentions:    ms,
+			CreatedAt:   c.CreatedAt,
+			UpdatedAt:   c.UpdatedAt,
+		}
+	}
+	return out, nil
+}
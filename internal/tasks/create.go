@@ -2,12 +2,16 @@ package tasks
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/history"
+	"github.com/AJMerr/hydianflow/internal/realtime"
 	"github.com/AJMerr/hydianflow/internal/utils"
+	"github.com/AJMerr/hydianflow/internal/workflow"
+	"gorm.io/gorm"
 )
 
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
@@ -27,10 +31,28 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Subtasks default to their parent's project
+	if req.ParentTaskID != nil && req.ProjectID == nil {
+		var parent database.Task
+		if err := h.DB.Select("id, project_id").First(&parent, *req.ParentTaskID).Error; err == nil {
+			req.ProjectID = parent.ProjectID
+		}
+	}
+
+	if req.ProjectID != nil && !h.canUseProject(w, *req.ProjectID, uid) {
+		return
+	}
+
+	wf, err := workflow.ForProject(h.DB, req.ProjectID)
+	if err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_workflow", "could not load project workflow")
+		return
+	}
+
 	// Defaults
-	status := "todo"
+	status := wf.First(database.StatusCategoryTodo)
 	if req.Status != nil {
-		if s, ok := normalStatus(*req.Status); ok {
+		if s, ok := wf.Normalize(*req.Status); ok {
 			status = s
 		} else {
 			utils.Error(w, http.StatusBadRequest, "validation", "invalid status")
@@ -38,19 +60,15 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Position (scoped per status/creator, and per project if provided)
-	pos := 1000.0
+	// Position (scoped per status and project, or per creator for personal tasks)
+	pos := positionStep
 	if req.Position != nil {
 		pos = *req.Position
 	} else {
 		var maxPos float64
-		posQ := h.DB.Model(&database.Task{}).
-			Where("status = ? AND creator_id = ?", status, uid)
-		if req.ProjectID != nil {
-			posQ = posQ.Where("project_id = ?", *req.ProjectID)
-		}
-		_ = posQ.Select("COALESCE(MAX(position), 0)").Scan(&maxPos).Error
-		pos = maxPos + 1000
+		_ = columnScope(h.DB, req.ProjectID, uid, database.TaskStatus(status)).
+			Select("COALESCE(MAX(position), 0)").Scan(&maxPos).Error
+		pos = maxPos + positionStep
 	}
 
 	t := database.Task{
@@ -64,46 +82,81 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	if req.Description != nil {
 		t.Description = *req.Description
 	}
-	if req.AssigneeID != nil {
+	if req.AssigneeID != nil && *req.AssigneeID != 0 {
 		t.AssigneeID = req.AssigneeID
 	}
 	if req.ProjectID != nil {
 		t.ProjectID = req.ProjectID
-
-		var p database.Project
-		if err := h.DB.Where("id = ? AND owner_id = ?", *req.ProjectID, uid).First(&p).Error; err != nil {
-			utils.Error(w, http.StatusForbidden, "forbidden", "invalid project")
-			return
+	}
+	if err := checkAssignee(h.DB, t); err != nil {
+		if !writeTaskError(w, err) {
+			utils.Error(w, http.StatusInternalServerError, "db_access", "could not check assignee")
 		}
+		return
 	}
 
-	now := time.Now().UTC()
-	if status == "in_progress" {
-		t.StartedAt = &now
+	wf.Stamp(&t, time.Now().UTC())
+
+	if req.AutoComplete != nil {
+		t.AutoComplete = *req.AutoComplete
 	}
-	if status == "done" {
-		t.CompletedAt = &now
+	if msg := planningFields(&t, req.DueAt, req.Priority, req.EstimatePoints); msg != "" {
+		utils.Error(w, http.StatusBadRequest, "validation", msg)
+		return
+	}
+	if req.ParentTaskID != nil {
+		if err := checkParent(h.DB, t, *req.ParentTaskID); err != nil {
+			if errors.Is(err, errBadParent) {
+				utils.Error(w, http.StatusBadRequest, "validation", "parent task not found or in another project")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "db_parent", "could not load parent task")
+			return
+		}
+		t.ParentTaskID = req.ParentTaskID
 	}
 
 	if req.Tag != nil {
-		tag := strings.ToLower(strings.TrimSpace(*req.Tag))
-		switch tag {
-		case "":
-			req.Tag = nil
-		case "feature", "feature_request", "issue":
-			req.Tag = &tag
-		default:
+		tag, ok := normalTag(*req.Tag)
+		if !ok {
 			utils.Error(w, http.StatusBadRequest, "validation", "invalid tag")
 			return
 		}
+		if tag != nil && t.ProjectID != nil {
+			utils.Error(w, http.StatusBadRequest, "validation", msgProjectTag)
+			return
+		}
+		t.Tag = tag
 	}
-	if req.Tag != nil {
-		t.Tag = req.Tag
+	labelIDs, err := checkLabels(h.DB, t.ProjectID, req.LabelIDs)
+	if err != nil {
+		if errors.Is(err, errBadLabels) {
+			utils.Error(w, http.StatusBadRequest, "validation", "labels must belong to the task's project")
+			return
+		}
+		utils.Error(w, http.StatusInternalServerError, "db_labels", "could not load labels")
+		return
 	}
 
-	if err := h.DB.Create(&t).Error; err != nil {
+	if err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&t).Error; err != nil {
+			return err
+		}
+		if err := history.Record(tx, t.ID, history.UserActor(uid), history.Change{
+			Field:    history.FieldCreated,
+			NewValue: history.Str(t.Title),
+		}); err != nil {
+			return err
+		}
+		if len(labelIDs) > 0 {
+			if err := setLabels(tx, t.ID, history.UserActor(uid), labelIDs); err != nil {
+				return err
+			}
+		}
+		return realtime.PublishTask(tx, realtime.TaskCreated, realtime.SourceUser, t)
+	}); err != nil {
 		utils.Error(w, http.StatusInternalServerError, "db_create", "could not create task")
 		return
 	}
-	utils.JSON(w, http.StatusCreated, toResp(t))
+	h.writeTask(w, http.StatusCreated, t)
 }
@@ -2,11 +2,13 @@ package tasks
 
 import (
 	"net/http"
-	"strconv"
 
+	"github.com/AJMerr/hydianflow/internal/access"
 	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/history"
+	"github.com/AJMerr/hydianflow/internal/realtime"
 	"github.com/AJMerr/hydianflow/internal/utils"
-	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
 )
 
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
@@ -15,9 +17,34 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 		utils.Error(w, http.StatusUnauthorized, "unauthorized", "login required")
 		return
 	}
-	id, _ := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
 
-	if err := h.DB.Where("id = ? AND creator_id = ?", id, uid).Delete(&database.Task{}).Error; err != nil {
+	t, ok := h.loadTask(w, r, uid, database.ProjectRoleMember)
+	if !ok {
+		return
+	}
+	// Members may only delete their own tasks; admins and owners may delete any
+	if t.CreatorID != uid {
+		if _, err := access.RequireTask(h.DB, t, uid, database.ProjectRoleAdmin); err != nil {
+			access.WriteError(w, err, "task not found")
+			return
+		}
+	}
+
+	if err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&t).Error; err != nil {
+			return err
+		}
+		if err := detachChildren(tx, t.ID); err != nil {
+			return err
+		}
+		if err := history.Record(tx, t.ID, history.UserActor(uid), history.Change{
+			Field:    history.FieldDeleted,
+			OldValue: history.Str(t.Title),
+		}); err != nil {
+			return err
+		}
+		return realtime.PublishTask(tx, realtime.TaskDeleted, realtime.SourceUser, t)
+	}); err != nil {
 		utils.Error(w, http.StatusInternalServerError, "db_delete", "failed to delete task")
 		return
 	}
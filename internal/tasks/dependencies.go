@@ -0,0 +1,324 @@
+package tasks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/history"
+	"github.com/AJMerr/hydianflow/internal/realtime"
+	"github.com/AJMerr/hydianflow/internal/utils"
+	"github.com/AJMerr/hydianflow/internal/workflow"
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DependencyRequest links the task in the URL to another one. Set exactly one
+// of BlockerID (the other task blocks this one) or BlockedID (this task
+// blocks the other one).
+type DependencyRequest struct {
+	BlockerID *uint `json:"blocker_id,omitempty"`
+	BlockedID *uint `json:"blocked_id,omitempty"`
+}
+
+type DependencyTask struct {
+	ID     uint   `json:"id"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+}
+
+type DependenciesResponse struct {
+	BlockedBy []DependencyTask `json:"blocked_by"`
+	Blocks    []DependencyTask `json:"blocks"`
+}
+
+// blockedError is returned when a blocked task may not be started.
+type blockedError struct {
+	Blockers []uint
+}
+
+func (e blockedError) Error() string {
+	return fmt.Sprintf("task is blocked by %v", e.Blockers)
+}
+
+// checkStart applies the project's blocked policy when t moves into a doing
+// status. It returns a warning when the policy allows the move anyway.
+func checkStart(db *gorm.DB, wf workflow.Workflow, before, t database.Task) (string, error) {
+	if t.Status == before.Status {
+		return "", nil
+	}
+	if cat, _ := wf.Category(string(t.Status)); cat != database.StatusCategoryDoing {
+		return "", nil
+	}
+	open, err := workflow.OpenBlockers(db, []uint{t.ID})
+	if err != nil {
+		return "", err
+	}
+	blockers := open[t.ID]
+	if len(blockers) == 0 {
+		return "", nil
+	}
+
+	policy := database.BlockedPolicyRefuse
+	if t.ProjectID != nil {
+		if err := db.Model(&database.Project{}).
+			Where("id = ?", *t.ProjectID).
+			Pluck("blocked_policy", &policy).Error; err != nil {
+			return "", err
+		}
+	}
+	if policy == database.BlockedPolicyWarn {
+		return blockedError{Blockers: blockers}.Error(), nil
+	}
+	return "", blockedError{Blockers: blockers}
+}
+
+// enteredDone reports whether a task moved into a done status, from's
+// workflow applying before the change and to's after it.
+func enteredDone(from, to workflow.Workflow, before, after database.Task) bool {
+	was, _ := from.Category(string(before.Status))
+	is, _ := to.Category(string(after.Status))
+	return is == database.StatusCategoryDone && was != database.StatusCategoryDone
+}
+
+// finishChange completes the parents a change may have finished and releases
+// the dependents of every task that ends up done, t included when done is
+// set. Call it inside the transaction that saved t.
+func finishChange(tx *gorm.DB, actor history.Actor, before, t database.Task, done bool) error {
+	completed, err := workflow.CompleteParents(tx, actor, realtime.SourceUser, parentsToCheck(before, t)...)
+	if err != nil {
+		return err
+	}
+	if done {
+		completed = append(completed, t.ID)
+	}
+	_, err = workflow.ReleaseDependents(tx, actor, realtime.SourceUser, completed)
+	return err
+}
+
+// writeBlocked writes the 409 for a refused start. It reports whether err was
+// a blockedError.
+func writeBlocked(w http.ResponseWriter, err error) bool {
+	var be blockedError
+	if !errors.As(err, &be) {
+		return false
+	}
+	type blockedBody struct {
+		utils.ErrBody
+		BlockedBy []uint `json:"blocked_by"`
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	_ = json.NewEncoder(w).Encode(map[string]any{"error": blockedBody{
+		ErrBody:   utils.ErrBody{Code: "blocked", Message: "task is blocked by unfinished tasks"},
+		BlockedBy: be.Blockers,
+	}})
+	return true
+}
+
+// GET /api/v1/tasks/{id}/dependencies
+func (h *Handler) ListDependencies(w http.ResponseWriter, r *http.Request) {
+	uid, ok := mustUserID(r)
+	if !ok {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "login required")
+		return
+	}
+
+	t, ok := h.loadTask(w, r, uid, database.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+
+	load := func(join, where string) ([]DependencyTask, error) {
+		out := []DependencyTask{}
+		err := h.DB.Table("task_dependencies d").
+			Select("t.id, t.title, t.status").
+			Joins("JOIN tasks t ON "+join+" AND t.deleted_at IS NULL").
+			Where(where, t.ID).
+			Order("t.id").
+			Scan(&out).Error
+		return out, err
+	}
+	blockedBy, err := load("t.id = d.blocker_id", "d.blocked_id = ?")
+	if err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_list", "could not load dependencies")
+		return
+	}
+	blocks, err := load("t.id = d.blocked_id", "d.blocker_id = ?")
+	if err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_list", "could not load dependencies")
+		return
+	}
+	utils.JSON(w, http.StatusOK, DependenciesResponse{BlockedBy: blockedBy, Blocks: blocks})
+}
+
+var errDependencyCycle = errors.New("dependency would create a cycle")
+
+// Advisory lock namespaces for a board's dependency graph; the project id, or
+// the creator id for personal tasks, fills the low 48 bits
+const (
+	projectDepsLock  int64 = 0x6470 << 48 // "dp"
+	personalDepsLock int64 = 0x6475 << 48 // "du"
+)
+
+// depsLockKey names the advisory lock that serializes dependency changes on
+// t's board, so concurrent cycle checks see each other's edges.
+func depsLockKey(t database.Task) int64 {
+	if t.ProjectID != nil {
+		return projectDepsLock | int64(*t.ProjectID)
+	}
+	return personalDepsLock | int64(t.CreatorID)
+}
+
+// POST /api/v1/tasks/{id}/dependencies
+func (h *Handler) AddDependency(w http.ResponseWriter, r *http.Request) {
+	uid, ok := mustUserID(r)
+	if !ok {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "login required")
+		return
+	}
+
+	t, ok := h.loadTask(w, r, uid, database.ProjectRoleMember)
+	if !ok {
+		return
+	}
+
+	var req DependencyRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		utils.Error(w, http.StatusBadRequest, "bad_json", "invalid JSON body")
+		return
+	}
+	if (req.BlockerID == nil) == (req.BlockedID == nil) {
+		utils.Error(w, http.StatusBadRequest, "validation", "set exactly one of blocker_id or blocked_id")
+		return
+	}
+
+	otherID := req.BlockedID
+	if req.BlockerID != nil {
+		otherID = req.BlockerID
+	}
+	var other database.Task
+	if err := h.DB.First(&other, *otherID).Error; err != nil || other.ID == t.ID || !sameBoard(t, other) {
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.Error(w, http.StatusInternalServerError, "db_get", "could not load task")
+			return
+		}
+		utils.Error(w, http.StatusBadRequest, "validation", "dependent task not found or in another project")
+		return
+	}
+
+	dep := database.TaskDependency{BlockerID: t.ID, BlockedID: other.ID, CreatedByID: &uid, CreatedAt: time.Now().UTC()}
+	if req.BlockerID != nil {
+		dep.BlockerID, dep.BlockedID = other.ID, t.ID
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		// Both tasks are on the same board, so one lock covers every edge the
+		// cycle check can reach
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", depsLockKey(t)).Error; err != nil {
+			return err
+		}
+
+		// The new edge closes a cycle if the blocked task already blocks the blocker
+		var cycle bool
+		if err := tx.Raw(`
+			WITH RECURSIVE down AS (
+				SELECT blocked_id AS id FROM task_dependencies WHERE blocker_id = ?
+				UNION
+				SELECT d.blocked_id FROM task_dependencies d JOIN down ON d.blocker_id = down.id
+			)
+			SELECT EXISTS (SELECT 1 FROM down WHERE id = ?)`, dep.BlockedID, dep.BlockerID).
+			Scan(&cycle).Error; err != nil {
+			return err
+		}
+		if cycle {
+			return errDependencyCycle
+		}
+
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&dep)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		if err := history.Record(tx, dep.BlockedID, history.UserActor(uid), history.Change{
+			Field:    history.FieldBlockedBy,
+			NewValue: history.Str(strconv.FormatUint(uint64(dep.BlockerID), 10)),
+		}); err != nil {
+			return err
+		}
+		blocked := t
+		if dep.BlockedID != t.ID {
+			blocked = other
+		}
+		return realtime.PublishTask(tx, realtime.TaskUpdated, realtime.SourceUser, blocked)
+	})
+	if errors.Is(err, errDependencyCycle) {
+		utils.Error(w, http.StatusConflict, "cycle", "dependency would create a cycle")
+		return
+	}
+	if err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_create", "could not add dependency")
+		return
+	}
+	utils.JSON(w, http.StatusCreated, dep)
+}
+
+// DELETE /api/v1/tasks/{id}/dependencies/{otherID}
+//
+// Removes the link between the two tasks in whichever direction it exists.
+func (h *Handler) RemoveDependency(w http.ResponseWriter, r *http.Request) {
+	uid, ok := mustUserID(r)
+	if !ok {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "login required")
+		return
+	}
+
+	t, ok := h.loadTask(w, r, uid, database.ProjectRoleMember)
+	if !ok {
+		return
+	}
+	other64, err := strconv.ParseUint(chi.URLParam(r, "otherID"), 10, 64)
+	if err != nil || other64 == 0 {
+		utils.Error(w, http.StatusBadRequest, "bad_id", "invalid task id")
+		return
+	}
+	otherID := uint(other64)
+
+	var removed []database.TaskDependency
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Returning{}).
+			Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)",
+				t.ID, otherID, otherID, t.ID).
+			Delete(&removed).Error; err != nil {
+			return err
+		}
+		actor := history.UserActor(uid)
+		for _, d := range removed {
+			if err := history.Record(tx, d.BlockedID, actor, history.Change{
+				Field:    history.FieldBlockedBy,
+				OldValue: history.Str(strconv.FormatUint(uint64(d.BlockerID), 10)),
+			}); err != nil {
+				return err
+			}
+		}
+		if len(removed) > 0 {
+			return realtime.PublishTask(tx, realtime.TaskUpdated, realtime.SourceUser, t)
+		}
+		return nil
+	})
+	if err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_delete", "could not remove dependency")
+		return
+	}
+	if len(removed) == 0 {
+		utils.Error(w, http.StatusNotFound, "not_found", "dependency not found")
+		return
+	}
+	utils.JSON(w, http.StatusOK, map[string]string{"ok": "true"})
+}
@@ -0,0 +1,40 @@
+package tasks
+
+import (
+	"testing"
+
+	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/workflow"
+)
+
+func TestEnteredDone(t *testing.T) {
+	def := workflow.Default()
+	custom := workflow.Workflow{Statuses: []workflow.Status{
+		{Key: "backlog", Category: database.StatusCategoryTodo},
+		{Key: "review", Category: database.StatusCategoryDoing},
+		{Key: "shipped", Category: database.StatusCategoryDone},
+		{Key: "archived", Category: database.StatusCategoryDone},
+	}}
+	task := func(s string) database.Task { return database.Task{Status: database.TaskStatus(s)} }
+
+	tests := []struct {
+		name          string
+		from, to      workflow.Workflow
+		before, after string
+		want          bool
+	}{
+		{"todo to done", def, def, "todo", "done", true},
+		{"doing to done", def, def, "in_progress", "done", true},
+		{"still done", def, def, "done", "done", false},
+		{"done to done elsewhere", custom, custom, "shipped", "archived", false},
+		{"reopened", def, def, "done", "todo", false},
+		{"not done", def, def, "todo", "in_progress", false},
+		{"moved project into done", def, custom, "in_progress", "shipped", true},
+		{"moved project, done both sides", def, custom, "done", "shipped", false},
+	}
+	for _, tt := range tests {
+		if got := enteredDone(tt.from, tt.to, task(tt.before), task(tt.after)); got != tt.want {
+			t.Errorf("%s: enteredDone = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
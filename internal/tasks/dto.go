@@ -3,43 +3,84 @@ package tasks
 import "time"
 
 type TaskCreateRequest struct {
-	Title       string   `json:"title"`
-	Description *string  `json:"description,omitempty"`
-	Tag         *string  `json:"tag,omitempty"`
-	Status      *string  `json:"status,omitempty"`
-	Position    *float64 `json:"position,omitempty"`
-	RepoName    *string  `json:"repo_full_name,omitempty"`
-	BranchHint  *string  `json:"branch_hint,omitempty"`
-	ProjectID   *uint    `json:"project_id,omitempty"`
-	AssigneeID  *uint    `json:"assignee_id,omitempty"`
+	Title       string  `json:"title"`
+	Description *string `json:"description,omitempty"`
+	// Tag is the legacy fixed tag; project tasks use LabelIDs instead
+	Tag        *string  `json:"tag,omitempty"`
+	Status     *string  `json:"status,omitempty"`
+	Position   *float64 `json:"position,omitempty"`
+	RepoName   *string  `json:"repo_full_name,omitempty"`
+	BranchHint *string  `json:"branch_hint,omitempty"`
+	ProjectID  *uint    `json:"project_id,omitempty"`
+	AssigneeID *uint    `json:"assignee_id,omitempty"`
+	// ParentTaskID nests the task; it defaults to the parent's project
+	ParentTaskID   *uint   `json:"parent_task_id,omitempty"`
+	AutoComplete   *bool   `json:"auto_complete,omitempty"`
+	DueAt          *string `json:"due_at,omitempty"`
+	Priority       *string `json:"priority,omitempty"`
+	EstimatePoints *int    `json:"estimate_points,omitempty"`
+	LabelIDs       []uint  `json:"label_ids,omitempty"`
 }
 
 type TaskUpdateRequest struct {
-	Title       *string  `json:"title,omitempty"`
-	Description *string  `json:"description,omitempty"`
-	Tag         *string  `json:"tag,omitempty"`
-	Status      *string  `json:"status,omitempty"`
-	AssigneeID  *uint    `json:"assignee_id,omitempty"`
-	Position    *float64 `json:"position,omitempty"`
-	RepoName    *string  `json:"repo_full_name,omitempty"`
-	BranchHint  *string  `json:"branch_hint,omitempty"`
-	ProjectID   *uint    `json:"project_id,omitempty"`
+	Title       *string `json:"title,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Tag         *string `json:"tag,omitempty"`
+	Status      *string `json:"status,omitempty"`
+	// 0 unassigns the task
+	AssigneeID *uint    `json:"assignee_id,omitempty"`
+	Position   *float64 `json:"position,omitempty"`
+	RepoName   *string  `json:"repo_full_name,omitempty"`
+	BranchHint *string  `json:"branch_hint,omitempty"`
+	ProjectID  *uint    `json:"project_id,omitempty"`
+	// 0 detaches the task from its parent
+	ParentTaskID *uint `json:"parent_task_id,omitempty"`
+	AutoComplete *bool `json:"auto_complete,omitempty"`
+	// "" clears the due date or priority, 0 clears the estimate
+	DueAt          *string `json:"due_at,omitempty"`
+	Priority       *string `json:"priority,omitempty"`
+	EstimatePoints *int    `json:"estimate_points,omitempty"`
+	// LabelIDs replaces the task's labels; [] removes them all
+	LabelIDs *[]uint `json:"label_ids,omitempty"`
+}
+
+// Progress counts done children or checked checklist items.
+type Progress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
 }
 
 type TaskResponse struct {
-	ID          uint       `json:"id"`
-	Title       string     `json:"title"`
-	Description *string    `json:"description,omitempty"`
-	Tag         *string    `json:"tag,omitempty"`
-	Status      string     `json:"status"`
-	Position    float64    `json:"position"`
-	CreatorID   uint       `json:"creator_id"`
-	AssigneeID  *uint      `json:"assignee_id,omitempty"`
-	RepoName    *string    `json:"repo_full_name,omitempty"`
-	BranchHint  *string    `json:"branch_hint,omitempty"`
-	ProjectID   *uint      `json:"project_id,omitempty"`
-	StartedAt   *time.Time `json:"started_at,omitempty"`
-	CompletedAt *time.Time `json:"completed_at,omitempty"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	ID             uint            `json:"id"`
+	Title          string          `json:"title"`
+	Description    *string         `json:"description,omitempty"`
+	Tag            *string         `json:"tag,omitempty"`
+	Labels         []LabelResponse `json:"labels,omitempty"`
+	Status         string          `json:"status"`
+	Position       float64         `json:"position"`
+	CreatorID      uint            `json:"creator_id"`
+	AssigneeID     *uint           `json:"assignee_id,omitempty"`
+	RepoName       *string         `json:"repo_full_name,omitempty"`
+	BranchHint     *string         `json:"branch_hint,omitempty"`
+	PRNumber       *int            `json:"pr_number,omitempty"`
+	PRURL          *string         `json:"pr_url,omitempty"`
+	PRState        *string         `json:"pr_state,omitempty"`
+	PRDraft        bool            `json:"pr_draft,omitempty"`
+	IssueNumber    *int            `json:"issue_number,omitempty"`
+	ProjectID      *uint           `json:"project_id,omitempty"`
+	ParentTaskID   *uint           `json:"parent_task_id,omitempty"`
+	AutoComplete   bool            `json:"auto_complete"`
+	DueAt          *time.Time      `json:"due_at,omitempty"`
+	Priority       *string         `json:"priority,omitempty"`
+	EstimatePoints *int            `json:"estimate_points,omitempty"`
+	TemplateID     *uint           `json:"template_id,omitempty"`
+	Subtasks       *Progress       `json:"subtasks,omitempty"`
+	Checklist      *Progress       `json:"checklist,omitempty"`
+	IsBlocked      bool            `json:"is_blocked"`
+	BlockedBy      []uint          `json:"blocked_by,omitempty"`
+	Warnings       []string        `json:"warnings,omitempty"`
+	StartedAt      *time.Time      `json:"started_at,omitempty"`
+	CompletedAt    *time.Time      `json:"completed_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
 }
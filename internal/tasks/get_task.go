@@ -3,12 +3,19 @@ package tasks
 import (
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/AJMerr/hydianflow/internal/access"
 	"github.com/AJMerr/hydianflow/internal/database"
 	"github.com/AJMerr/hydianflow/internal/utils"
+	"github.com/AJMerr/hydianflow/internal/workflow"
 )
 
-// Get All Tasks
+// GET /api/v1/tasks
+//
+// Filters compose; sort is one of the sortKeys, prefixed with "-" for
+// descending. next_cursor is only valid for the same sort.
 func (h *Handler) GetAll(w http.ResponseWriter, r *http.Request) {
 	uid, ok := mustUserID(r)
 	if !ok {
@@ -16,51 +23,188 @@ func (h *Handler) GetAll(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	where := h.DB.Where("creator_id = ?", uid)
+	qs := r.URL.Query()
+	where := access.Tasks(h.DB, uid)
 
-	if pidStr := r.URL.Query().Get("project_id"); pidStr != "" {
+	var projectID *uint
+	if pidStr := qs.Get("project_id"); pidStr != "" {
 		pid, err := strconv.ParseUint(pidStr, 10, 64)
 		if err != nil {
 			utils.Error(w, http.StatusBadRequest, "validation", "invalid project_id")
 			return
 		}
-		where = where.Where("project_id = ?", uint(pid))
+		p := uint(pid)
+		projectID = &p
+		where = where.Where("tasks.project_id = ?", p)
 	}
 
-	if s := r.URL.Query().Get("status"); s != "" {
-		if ns, ok := normalStatus(s); ok {
-			where = where.Where("status = ?", ns)
+	if s := qs.Get("status"); s != "" {
+		ns, ok := normalStatus(s)
+		if projectID != nil {
+			wf, err := workflow.ForProject(h.DB, projectID)
+			if err != nil {
+				utils.Error(w, http.StatusInternalServerError, "db_workflow", "could not load project workflow")
+				return
+			}
+			ns, ok = wf.Normalize(s)
+		}
+		if ok {
+			where = where.Where("tasks.status = ?", ns)
 		} else {
 			utils.Error(w, http.StatusBadRequest, "validation", "invalid status")
 			return
 		}
 	}
 
+	if s := qs.Get("assignee_id"); s != "" {
+		if s == "none" {
+			where = where.Where("tasks.assignee_id IS NULL")
+		} else {
+			aid, err := strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				utils.Error(w, http.StatusBadRequest, "validation", "invalid assignee_id")
+				return
+			}
+			where = where.Where("tasks.assignee_id = ?", aid)
+		}
+	}
+	if s := strings.ToLower(strings.TrimSpace(qs.Get("tag"))); s != "" {
+		where = where.Where("tasks.tag = ?", s)
+	}
+	// label_ids=1,2 matches tasks with any of the labels, or all of them
+	// with label_match=all
+	if s := strings.TrimSpace(qs.Get("label_ids")); s != "" {
+		var ids []uint
+		for _, v := range strings.Split(s, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(v), 10, 64)
+			if err != nil || id == 0 {
+				utils.Error(w, http.StatusBadRequest, "validation", "invalid label_ids")
+				return
+			}
+			ids = append(ids, uint(id))
+		}
+		switch qs.Get("label_match") {
+		case "", "any":
+			where = where.Where("EXISTS (SELECT 1 FROM task_labels tl WHERE tl.task_id = tasks.id AND tl.label_id IN ?)", ids)
+		case "all":
+			where = where.Where("(SELECT COUNT(DISTINCT tl.label_id) FROM task_labels tl WHERE tl.task_id = tasks.id AND tl.label_id IN ?) = ?", ids, distinctCount(ids))
+		default:
+			utils.Error(w, http.StatusBadRequest, "validation", "label_match must be any or all")
+			return
+		}
+	}
+	if s := strings.TrimSpace(qs.Get("repo_full_name")); s != "" {
+		where = where.Where("tasks.repo_full_name = ?", s)
+	}
+	if s := strings.TrimSpace(qs.Get("branch_hint")); s != "" {
+		where = where.Where("tasks.branch_hint = ?", s)
+	}
+
+	if s := strings.TrimSpace(qs.Get("priority")); s != "" {
+		var ps []string
+		for _, v := range strings.Split(s, ",") {
+			p, ok := normalPriority(v)
+			if !ok || p == nil {
+				utils.Error(w, http.StatusBadRequest, "validation", "invalid priority")
+				return
+			}
+			ps = append(ps, string(*p))
+		}
+		where = where.Where("tasks.priority IN ?", ps)
+	}
+
+	// A slice rather than a map, so the first invalid date reported is stable
+	for _, f := range []struct{ param, cond string }{
+		{"created_after", "tasks.created_at > ?"},
+		{"updated_since", "tasks.updated_at >= ?"},
+		{"due_after", "tasks.due_at >= ?"},
+		{"due_before", "tasks.due_at < ?"},
+	} {
+		v := strings.TrimSpace(qs.Get(f.param))
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			utils.Error(w, http.StatusBadRequest, "validation", "invalid "+f.param+" (want RFC3339)")
+			return
+		}
+		where = where.Where(f.cond, t)
+	}
+	if s := qs.Get("parent_task_id"); s != "" {
+		if s == "none" {
+			where = where.Where("tasks.parent_task_id IS NULL")
+		} else {
+			pid, err := strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				utils.Error(w, http.StatusBadRequest, "validation", "invalid parent_task_id")
+				return
+			}
+			where = where.Where("tasks.parent_task_id = ?", pid)
+		}
+	}
+	if v := strings.TrimSpace(qs.Get("completed_between")); v != "" {
+		from, to, ok := parseTimeRange(v)
+		if !ok {
+			utils.Error(w, http.StatusBadRequest, "validation", "invalid completed_between (want RFC3339,RFC3339)")
+			return
+		}
+		where = where.Where("tasks.completed_at >= ? AND tasks.completed_at < ?", from, to)
+	}
+
+	sort, ok := parseSort(qs.Get("sort"))
+	if !ok {
+		utils.Error(w, http.StatusBadRequest, "validation", "invalid sort")
+		return
+	}
+	if c := qs.Get("cursor"); c != "" {
+		cur, err := decodeListCursor(c, sort)
+		if err != nil {
+			utils.Error(w, http.StatusBadRequest, "validation", "invalid cursor")
+			return
+		}
+		cond, args := sort.after(cur)
+		where = where.Where(cond, args...)
+	}
+
 	limit := parseLimit(r, 50, 100)
-	cursor := parseCursor(r)
 
 	var rows []database.Task
-	q := where.Order("position ASC, id ASC").Limit(limit)
-	if cursor > 0 {
-		q = q.Where("id > ?", cursor)
-	}
-	if err := q.Find(&rows).Error; err != nil {
+	if err := where.Order(sort.order()).Limit(limit).Find(&rows).Error; err != nil {
 		utils.Error(w, http.StatusInternalServerError, "db_list", "could not get all tasks")
 		return
 	}
 
-	nextCursor := uint(0)
-	if len(rows) > 0 {
-		nextCursor = rows[len(rows)-1].ID
+	nextCursor := ""
+	if len(rows) == limit {
+		nextCursor = sort.cursorFor(rows[len(rows)-1])
 	}
 
 	type listResp struct {
 		Items      []TaskResponse `json:"items"`
-		NextCursor uint           `json:"next_cursor"`
+		NextCursor string         `json:"next_cursor"`
 	}
-	items := make([]TaskResponse, len(rows))
-	for i, t := range rows {
-		items[i] = toResp(t)
+	items, err := toResps(h.DB, rows)
+	if err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_list", "could not get all tasks")
+		return
 	}
 	utils.JSON(w, http.StatusOK, listResp{Items: items, NextCursor: nextCursor})
 }
+
+// parseTimeRange parses "from,to" as two RFC3339 timestamps.
+func parseTimeRange(v string) (time.Time, time.Time, bool) {
+	a, b, ok := strings.Cut(v, ",")
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	from, err := time.Parse(time.RFC3339, strings.TrimSpace(a))
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	to, err := time.Parse(time.RFC3339, strings.TrimSpace(b))
+	if err != nil || !to.After(from) {
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
@@ -1,7 +1,14 @@
 package tasks
 
-import "gorm.io/gorm"
+import (
+	"github.com/AJMerr/hydianflow/internal/githubapi"
+	"gorm.io/gorm"
+)
 
 type Handler struct {
 	DB *gorm.DB
+	GH *githubapi.Service
+	// BranchTemplate names branches created from tasks; see
+	// DefaultBranchTemplate
+	BranchTemplate string
 }
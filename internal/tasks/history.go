@@ -0,0 +1,88 @@
+package tasks
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/utils"
+)
+
+type TaskEventActor struct {
+	Type        string  `json:"type"` // "user" or "webhook"
+	UserID      *uint   `json:"user_id,omitempty"`
+	GithubLogin *string `json:"github_login,omitempty"`
+	DeliveryID  *string `json:"delivery_id,omitempty"`
+}
+
+type TaskEventResponse struct {
+	ID        uint           `json:"id"`
+	Field     string         `json:"field"`
+	OldValue  *string        `json:"old_value"`
+	NewValue  *string        `json:"new_value"`
+	Actor     TaskEventActor `json:"actor"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// GET /api/v1/tasks/{id}/history
+func (h *Handler) History(w http.ResponseWriter, r *http.Request) {
+	uid, ok := mustUserID(r)
+	if !ok {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "login required")
+		return
+	}
+
+	t, ok := h.loadTask(w, r, uid, database.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+
+	limit := parseLimit(r, 50, 200)
+	cursor := parseCursor(r)
+
+	type row struct {
+		database.TaskEvent
+		GithubLogin *string
+	}
+	q := h.DB.Table("task_events te").
+		Select("te.*, u.github_login").
+		Joins("LEFT JOIN users u ON u.id = te.actor_user_id").
+		Where("te.task_id = ?", t.ID).
+		Order("te.id DESC").
+		Limit(limit)
+	if cursor > 0 {
+		q = q.Where("te.id < ?", cursor)
+	}
+	var rows []row
+	if err := q.Scan(&rows).Error; err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_list", "could not load task history")
+		return
+	}
+
+	items := make([]TaskEventResponse, len(rows))
+	for i, e := range rows {
+		actor := TaskEventActor{Type: "user", UserID: e.ActorUserID, GithubLogin: e.GithubLogin}
+		if e.ActorDeliveryID != nil {
+			actor = TaskEventActor{Type: "webhook", DeliveryID: e.ActorDeliveryID}
+		}
+		items[i] = TaskEventResponse{
+			ID:        e.ID,
+			Field:     e.Field,
+			OldValue:  e.OldValue,
+			NewValue:  e.NewValue,
+			Actor:     actor,
+			CreatedAt: e.CreatedAt,
+		}
+	}
+
+	nextCursor := uint(0)
+	if len(rows) == limit {
+		nextCursor = rows[len(rows)-1].ID
+	}
+
+	type listResp struct {
+		Items      []TaskEventResponse `json:"items"`
+		NextCursor uint                `json:"next_cursor"`
+	}
+	utils.JSON(w, http.StatusOK, listResp{Items: items, NextCursor: nextCursor})
+}
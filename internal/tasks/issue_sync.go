@@ -0,0 +1,37 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AJMerr/hydianflow/internal/database"
+)
+
+// syncIssue closes or reopens the GitHub issue linked to a task when the
+// task is completed or reopened, for projects with SyncIssueState on. It runs
+// after the change is committed with the caller's token; a failure doesn't
+// undo the change and is returned as a warning instead.
+func (h *Handler) syncIssue(ctx context.Context, uid uint, before, after database.Task) string {
+	if h.GH == nil || after.IssueNumber == nil || after.RepoName == nil || after.ProjectID == nil {
+		return ""
+	}
+	if (before.CompletedAt == nil) == (after.CompletedAt == nil) {
+		return ""
+	}
+
+	var sync bool
+	if err := h.DB.Model(&database.Project{}).
+		Where("id = ?", *after.ProjectID).
+		Pluck("sync_issue_state", &sync).Error; err != nil || !sync {
+		return ""
+	}
+
+	state := "open"
+	if after.CompletedAt != nil {
+		state = "closed"
+	}
+	if err := h.GH.SetIssueState(ctx, uid, *after.RepoName, *after.IssueNumber, state); err != nil {
+		return fmt.Sprintf("could not update GitHub issue #%d: %v", *after.IssueNumber, err)
+	}
+	return ""
+}
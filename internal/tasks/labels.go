@@ -0,0 +1,152 @@
+package tasks
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/history"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const maxTaskLabels = 20
+
+var errBadLabels = errors.New("invalid labels")
+
+type LabelResponse struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// checkLabels validates ids as the label set of a task in projectID and
+// returns them de-duplicated. Personal tasks have no labels.
+func checkLabels(db *gorm.DB, projectID *uint, ids []uint) ([]uint, error) {
+	out := make([]uint, 0, len(ids))
+	seen := make(map[uint]struct{}, len(ids))
+	for _, id := range ids {
+		if _, dup := seen[id]; dup {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	if len(out) == 0 {
+		return out, nil
+	}
+	if projectID == nil || len(out) > maxTaskLabels {
+		return nil, errBadLabels
+	}
+	var n int64
+	if err := db.Model(&database.Label{}).
+		Where("id IN ? AND project_id = ?", out, *projectID).
+		Count(&n).Error; err != nil {
+		return nil, err
+	}
+	if int(n) != len(out) {
+		return nil, errBadLabels
+	}
+	return out, nil
+}
+
+// Colors migration 000022 gave the labels it made from tags
+var tagColors = map[string]string{
+	"feature":         "#2563eb",
+	"feature_request": "#7c3aed",
+	"issue":           "#dc2626",
+}
+
+// labelForTag returns the project's label named after tag, creating it if
+// needed.
+func labelForTag(tx *gorm.DB, projectID uint, tag string) (uint, error) {
+	l := database.Label{ProjectID: projectID, Name: tag, Color: tagColors[tag]}
+	if l.Color == "" {
+		l.Color = "#6b7280"
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&l).Error; err != nil {
+		return 0, err
+	}
+	if l.ID != 0 {
+		return l.ID, nil
+	}
+	err := tx.Select("id").
+		Where("project_id = ? AND lower(name) = lower(?)", projectID, tag).
+		Take(&l).Error
+	return l.ID, err
+}
+
+// setLabels replaces the labels on a task and records the change.
+func setLabels(tx *gorm.DB, taskID uint, actor history.Actor, ids []uint) error {
+	before, err := labelNames(tx, taskID)
+	if err != nil {
+		return err
+	}
+	if err := tx.Where("task_id = ?", taskID).Delete(&database.TaskLabel{}).Error; err != nil {
+		return err
+	}
+	if len(ids) > 0 {
+		rows := make([]database.TaskLabel, len(ids))
+		for i, id := range ids {
+			rows[i] = database.TaskLabel{TaskID: taskID, LabelID: id}
+		}
+		if err := tx.Create(&rows).Error; err != nil {
+			return err
+		}
+	}
+	after, err := labelNames(tx, taskID)
+	if err != nil {
+		return err
+	}
+	if before == after {
+		return nil
+	}
+	change := history.Change{Field: history.FieldLabels}
+	if before != "" {
+		change.OldValue = history.Str(before)
+	}
+	if after != "" {
+		change.NewValue = history.Str(after)
+	}
+	return history.Record(tx, taskID, actor, change)
+}
+
+func labelNames(db *gorm.DB, taskID uint) (string, error) {
+	var names []string
+	err := db.Table("task_labels tl").
+		Joins("JOIN labels l ON l.id = tl.label_id").
+		Where("tl.task_id = ?", taskID).
+		Order("lower(l.name)").
+		Pluck("l.name", &names).Error
+	return strings.Join(names, ","), err
+}
+
+// loadLabels returns the labels of each task, keyed by task id.
+func loadLabels(db *gorm.DB, ids []uint) (map[uint][]LabelResponse, error) {
+	type row struct {
+		TaskID uint
+		LabelResponse
+	}
+	var rows []row
+	if err := db.Table("task_labels tl").
+		Select("tl.task_id, l.id, l.name, l.color").
+		Joins("JOIN labels l ON l.id = tl.label_id").
+		Where("tl.task_id IN ?", ids).
+		Order("lower(l.name), l.id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make(map[uint][]LabelResponse)
+	for _, r := range rows {
+		out[r.TaskID] = append(out[r.TaskID], r.LabelResponse)
+	}
+	return out, nil
+}
+
+func distinctCount(ids []uint) int {
+	seen := make(map[uint]struct{}, len(ids))
+	for _, id := range ids {
+		seen[id] = struct{}{}
+	}
+	return len(seen)
+}
@@ -0,0 +1,169 @@
+package tasks
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AJMerr/hydianflow/internal/database"
+)
+
+// sortKey is a column the task list can be ordered by. Every ordering is
+// tie-broken on id so the keyset cursor is unique.
+type sortKey struct {
+	expr string
+	// cast applied to the cursor value when comparing against expr
+	cast  string
+	value func(t database.Task) string
+}
+
+// timeValue formats a cursor timestamp; nil sorts as ifNil.
+func timeValue(t *time.Time, ifNil string) string {
+	if t == nil {
+		return ifNil
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// Higher is more urgent; tasks without a priority rank lowest
+const priorityRank = "CASE tasks.priority WHEN 'urgent' THEN 4 WHEN 'high' THEN 3 WHEN 'medium' THEN 2 WHEN 'low' THEN 1 ELSE 0 END"
+
+func priorityValue(p *database.TaskPriority) int {
+	if p == nil {
+		return 0
+	}
+	switch *p {
+	case database.TaskPriorityUrgent:
+		return 4
+	case database.TaskPriorityHigh:
+		return 3
+	case database.TaskPriorityMedium:
+		return 2
+	case database.TaskPriorityLow:
+		return 1
+	}
+	return 0
+}
+
+var sortKeys = map[string]sortKey{
+	"position": {
+		expr:  "tasks.position",
+		cast:  "float8",
+		value: func(t database.Task) string { return strconv.FormatFloat(t.Position, 'g', -1, 64) },
+	},
+	"created_at": {
+		expr:  "tasks.created_at",
+		cast:  "timestamptz",
+		value: func(t database.Task) string { return timeValue(&t.CreatedAt, "") },
+	},
+	"updated_at": {
+		expr:  "tasks.updated_at",
+		cast:  "timestamptz",
+		value: func(t database.Task) string { return timeValue(&t.UpdatedAt, "") },
+	},
+	// Open tasks sort as if completed at the beginning of time
+	"completed_at": {
+		expr:  "COALESCE(tasks.completed_at, '-infinity'::timestamptz)",
+		cast:  "timestamptz",
+		value: func(t database.Task) string { return timeValue(t.CompletedAt, "-infinity") },
+	},
+	// Tasks without a due date sort after every dated one
+	"due_at": {
+		expr:  "COALESCE(tasks.due_at, 'infinity'::timestamptz)",
+		cast:  "timestamptz",
+		value: func(t database.Task) string { return timeValue(t.DueAt, "infinity") },
+	},
+	"priority": {
+		expr:  priorityRank,
+		cast:  "int",
+		value: func(t database.Task) string { return strconv.Itoa(priorityValue(t.Priority)) },
+	},
+	"estimate_points": {
+		expr: "COALESCE(tasks.estimate_points, 0)",
+		cast: "int",
+		value: func(t database.Task) string {
+			if t.EstimatePoints == nil {
+				return "0"
+			}
+			return strconv.Itoa(*t.EstimatePoints)
+		},
+	},
+	"title": {
+		expr:  "tasks.title",
+		cast:  "text",
+		value: func(t database.Task) string { return t.Title },
+	},
+}
+
+// taskSort is a parsed ?sort= value, e.g. "-updated_at".
+type taskSort struct {
+	name string
+	key  sortKey
+	desc bool
+}
+
+func parseSort(s string) (taskSort, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		s = "position"
+	}
+	desc := strings.HasPrefix(s, "-")
+	name := strings.TrimPrefix(s, "-")
+	key, ok := sortKeys[name]
+	return taskSort{name: name, key: key, desc: desc}, ok
+}
+
+func (s taskSort) String() string {
+	if s.desc {
+		return "-" + s.name
+	}
+	return s.name
+}
+
+func (s taskSort) order() string {
+	dir := " ASC"
+	if s.desc {
+		dir = " DESC"
+	}
+	return s.key.expr + dir + ", tasks.id" + dir
+}
+
+// after returns the keyset condition for rows following the cursor.
+func (s taskSort) after(c listCursor) (string, []any) {
+	op := ">"
+	if s.desc {
+		op = "<"
+	}
+	return "(" + s.key.expr + ", tasks.id) " + op + " (?::" + s.key.cast + ", ?)", []any{c.Value, c.ID}
+}
+
+// listCursor is the position of the last row of a page. It records the sort
+// it was issued for so it can't be replayed against a different ordering.
+type listCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    uint   `json:"id"`
+}
+
+func (s taskSort) cursorFor(t database.Task) string {
+	raw, _ := json.Marshal(listCursor{Sort: s.String(), Value: s.key.value(t), ID: t.ID})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeListCursor(c string, s taskSort) (listCursor, error) {
+	var out listCursor
+	raw, err := base64.RawURLEncoding.DecodeString(c)
+	if err != nil {
+		return out, err
+	}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return out, err
+	}
+	if out.Sort != s.String() || out.ID == 0 {
+		return out, errors.New("cursor does not match sort")
+	}
+	return out, nil
+}
@@ -0,0 +1,113 @@
+package tasks
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/AJMerr/hydianflow/internal/database"
+)
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		in       string
+		wantName string
+		wantDesc bool
+		wantOK   bool
+	}{
+		{"", "position", false, true},
+		{"updated_at", "updated_at", false, true},
+		{" -due_at ", "due_at", true, true},
+		{"-bogus", "bogus", true, false},
+	}
+	for _, tt := range tests {
+		s, ok := parseSort(tt.in)
+		if s.name != tt.wantName || s.desc != tt.wantDesc || ok != tt.wantOK {
+			t.Errorf("parseSort(%q) = %q desc=%v ok=%v; want %q desc=%v ok=%v",
+				tt.in, s.name, s.desc, ok, tt.wantName, tt.wantDesc, tt.wantOK)
+		}
+	}
+}
+
+func TestListCursorRoundTrip(t *testing.T) {
+	due := time.Date(2026, 5, 1, 12, 30, 0, 123, time.FixedZone("CEST", 2*3600))
+	pts := 5
+	prio := database.TaskPriorityHigh
+	task := newTask(42, "Write tests", nil)
+	task.Position = 1.5
+	task.CreatedAt = due.Add(-time.Hour)
+	task.UpdatedAt = due
+	task.DueAt = &due
+	task.Priority = &prio
+	task.EstimatePoints = &pts
+
+	tests := []struct {
+		sort string
+		want string
+	}{
+		{"position", "1.5"},
+		{"-created_at", "2026-05-01T09:30:00.000000123Z"},
+		{"updated_at", "2026-05-01T10:30:00.000000123Z"},
+		{"completed_at", "-infinity"},
+		{"-due_at", "2026-05-01T10:30:00.000000123Z"},
+		{"priority", "3"},
+		{"estimate_points", "5"},
+		{"title", "Write tests"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			s, ok := parseSort(tt.sort)
+			if !ok {
+				t.Fatalf("parseSort(%q) failed", tt.sort)
+			}
+			c, err := decodeListCursor(s.cursorFor(task), s)
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if c.Value != tt.want || c.ID != task.ID {
+				t.Errorf("cursor = %q/%d, want %q/%d", c.Value, c.ID, tt.want, task.ID)
+			}
+		})
+	}
+}
+
+func TestListCursorDefaults(t *testing.T) {
+	task := newTask(1, "", nil)
+	tests := []struct {
+		sort string
+		want string
+	}{
+		{"due_at", "infinity"},
+		{"priority", "0"},
+		{"estimate_points", "0"},
+	}
+	for _, tt := range tests {
+		s, _ := parseSort(tt.sort)
+		if got := s.key.value(task); got != tt.want {
+			t.Errorf("%s value = %q, want %q", tt.sort, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeListCursorRejects(t *testing.T) {
+	asc, _ := parseSort("updated_at")
+	desc, _ := parseSort("-updated_at")
+	enc := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"other direction", desc.cursorFor(newTask(3, "", nil))},
+		{"other key", func() string { s, _ := parseSort("title"); return s.cursorFor(newTask(3, "", nil)) }()},
+		{"not base64", "%%%"},
+		{"not json", enc("updated_at|x|3")},
+		{"zero id", enc(`{"s":"updated_at","v":"x","id":0}`)},
+		{"missing sort", enc(`{"v":"x","id":3}`)},
+	}
+	for _, tt := range tests {
+		if _, err := decodeListCursor(tt.cursor, asc); err == nil {
+			t.Errorf("%s: cursor accepted", tt.name)
+		}
+	}
+}
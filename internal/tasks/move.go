@@ -0,0 +1,211 @@
+package tasks
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/history"
+	"github.com/AJMerr/hydianflow/internal/realtime"
+	"github.com/AJMerr/hydianflow/internal/utils"
+	"github.com/AJMerr/hydianflow/internal/workflow"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	positionStep = 1000.0
+	// Below this gap a column is renumbered before inserting
+	minPositionGap = 1e-6
+)
+
+var errBadNeighbor = errors.New("neighbor is not in the target column")
+
+// TaskMoveRequest places a task in a column. BeforeID is the task that should
+// end up directly above the moved one and AfterID the one directly below;
+// either may be omitted. With neither, the task goes to the bottom.
+type TaskMoveRequest struct {
+	Status   *string `json:"status,omitempty"`
+	BeforeID *uint   `json:"before_id,omitempty"`
+	AfterID  *uint   `json:"after_id,omitempty"`
+}
+
+// columnScope limits a query to the tasks sharing a board column with a task
+// in the given project (or the creator's personal tasks) and status.
+func columnScope(db *gorm.DB, projectID *uint, creatorID uint, status database.TaskStatus) *gorm.DB {
+	q := db.Model(&database.Task{}).Where("status = ?", status)
+	if projectID != nil {
+		return q.Where("project_id = ?", *projectID)
+	}
+	return q.Where("creator_id = ? AND project_id IS NULL", creatorID)
+}
+
+// POST /api/v1/tasks/{id}/move
+func (h *Handler) Move(w http.ResponseWriter, r *http.Request) {
+	uid, ok := mustUserID(r)
+	if !ok {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "login required")
+		return
+	}
+
+	t, ok := h.loadTask(w, r, uid, database.ProjectRoleMember)
+	if !ok {
+		return
+	}
+	before := t
+
+	var req TaskMoveRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		utils.Error(w, http.StatusBadRequest, "bad_json", "invalid JSON body")
+		return
+	}
+	if (req.BeforeID != nil && *req.BeforeID == t.ID) || (req.AfterID != nil && *req.AfterID == t.ID) {
+		utils.Error(w, http.StatusBadRequest, "validation", "a task cannot be its own neighbor")
+		return
+	}
+
+	wf, err := workflow.ForProject(h.DB, t.ProjectID)
+	if err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_workflow", "could not load project workflow")
+		return
+	}
+	if req.Status != nil {
+		s, ok := wf.Normalize(*req.Status)
+		if !ok {
+			utils.Error(w, http.StatusBadRequest, "validation", "invalid status")
+			return
+		}
+		t.Status = database.TaskStatus(s)
+	}
+	if t.Status != before.Status {
+		wf.Stamp(&t, time.Now().UTC())
+	}
+
+	warning, err := checkStart(h.DB, wf, before, t)
+	if err != nil {
+		if !writeBlocked(w, err) {
+			utils.Error(w, http.StatusInternalServerError, "db_get", "could not check task dependencies")
+		}
+		return
+	}
+	var warnings []string
+	if warning != "" {
+		warnings = append(warnings, warning)
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		type slot struct {
+			ID       uint
+			Position float64
+		}
+		var col []slot
+		if err := columnScope(tx, t.ProjectID, t.CreatorID, t.Status).
+			Select("id, position").
+			Where("id <> ?", t.ID).
+			Order("position ASC, id ASC").
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Scan(&col).Error; err != nil {
+			return err
+		}
+
+		// gap returns the positions the moved task must fall between
+		gap := func() (float64, float64, error) {
+			idx := func(id *uint) int {
+				if id == nil {
+					return -2
+				}
+				for i, s := range col {
+					if s.ID == *id {
+						return i
+					}
+				}
+				return -1
+			}
+			bi, ai := idx(req.BeforeID), idx(req.AfterID)
+			if bi == -1 || ai == -1 {
+				return 0, 0, errBadNeighbor
+			}
+			switch {
+			case bi >= 0 && ai >= 0:
+				if ai != bi+1 {
+					return 0, 0, errBadNeighbor
+				}
+			case bi >= 0:
+				ai = bi + 1
+			case ai >= 0:
+				bi = ai - 1
+			default:
+				bi, ai = len(col)-1, len(col)
+			}
+			lo, hi := 0.0, 0.0
+			if bi >= 0 {
+				lo = col[bi].Position
+			}
+			if ai < len(col) {
+				hi = col[ai].Position
+			} else {
+				hi = lo + 2*positionStep
+			}
+			if bi < 0 {
+				lo = hi - 2*positionStep
+			}
+			return lo, hi, nil
+		}
+
+		lo, hi, err := gap()
+		if err != nil {
+			return err
+		}
+		if hi-lo < minPositionGap {
+			if err := rebalance(tx, t); err != nil {
+				return err
+			}
+			for i := range col {
+				col[i].Position = float64(i+1) * positionStep
+			}
+			if lo, hi, err = gap(); err != nil {
+				return err
+			}
+		}
+		t.Position = lo + (hi-lo)/2
+
+		if err := tx.Save(&t).Error; err != nil {
+			return err
+		}
+		if err := history.Record(tx, t.ID, history.UserActor(uid), history.Diff(before, t)...); err != nil {
+			return err
+		}
+		if err := realtime.PublishTask(tx, realtime.TaskUpdated, realtime.SourceUser, t); err != nil {
+			return err
+		}
+		return finishChange(tx, history.UserActor(uid), before, t, enteredDone(wf, wf, before, t))
+	})
+	if errors.Is(err, errBadNeighbor) {
+		utils.Error(w, http.StatusBadRequest, "validation", "before_id and after_id must be adjacent tasks in the target column")
+		return
+	}
+	if err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_update", "could not move task")
+		return
+	}
+	if warning := h.syncIssue(r.Context(), uid, before, t); warning != "" {
+		warnings = append(warnings, warning)
+	}
+	h.writeTask(w, http.StatusOK, t, warnings...)
+}
+
+// rebalance renumbers a column (excluding the task being moved) to evenly
+// spaced positions in its current order. The rows must already be locked.
+func rebalance(tx *gorm.DB, t database.Task) error {
+	col := columnScope(tx, t.ProjectID, t.CreatorID, t.Status).
+		Select("id, row_number() OVER (ORDER BY position ASC, id ASC) AS rn").
+		Where("id <> ?", t.ID)
+	return tx.Exec(`
+		UPDATE tasks SET position = r.rn * ?
+		FROM (?) AS r
+		WHERE tasks.id = r.id`, positionStep, col).Error
+}
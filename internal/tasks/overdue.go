@@ -0,0 +1,76 @@
+package tasks
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AJMerr/hydianflow/internal/access"
+	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/utils"
+)
+
+// GET /api/v1/tasks/overdue?assignee_id=&cursor=
+//
+// Incomplete tasks past their due date across every project the caller can
+// see, most overdue first. CompletedAt is kept in line with each project's
+// done statuses, so it doubles as the "incomplete" test here.
+func (h *Handler) Overdue(w http.ResponseWriter, r *http.Request) {
+	uid, ok := mustUserID(r)
+	if !ok {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "login required")
+		return
+	}
+
+	qs := r.URL.Query()
+	where := access.Tasks(h.DB, uid).
+		Where("tasks.due_at < ? AND tasks.completed_at IS NULL", time.Now().UTC())
+
+	if s := qs.Get("assignee_id"); s != "" {
+		if s == "me" {
+			where = where.Where("tasks.assignee_id = ?", uid)
+		} else {
+			aid, err := strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				utils.Error(w, http.StatusBadRequest, "validation", "invalid assignee_id")
+				return
+			}
+			where = where.Where("tasks.assignee_id = ?", aid)
+		}
+	}
+
+	sort, _ := parseSort("due_at")
+	if c := qs.Get("cursor"); c != "" {
+		cur, err := decodeListCursor(c, sort)
+		if err != nil {
+			utils.Error(w, http.StatusBadRequest, "validation", "invalid cursor")
+			return
+		}
+		cond, args := sort.after(cur)
+		where = where.Where(cond, args...)
+	}
+
+	limit := parseLimit(r, 50, 100)
+
+	var rows []database.Task
+	if err := where.Order(sort.order()).Limit(limit).Find(&rows).Error; err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_list", "could not get overdue tasks")
+		return
+	}
+	items, err := toResps(h.DB, rows)
+	if err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_list", "could not get overdue tasks")
+		return
+	}
+
+	nextCursor := ""
+	if len(rows) == limit {
+		nextCursor = sort.cursorFor(rows[len(rows)-1])
+	}
+
+	type listResp struct {
+		Items      []TaskResponse `json:"items"`
+		NextCursor string         `json:"next_cursor"`
+	}
+	utils.JSON(w, http.StatusOK, listResp{Items: items, NextCursor: nextCursor})
+}
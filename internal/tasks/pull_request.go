@@ -0,0 +1,152 @@
+package tasks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/githubhttp"
+	"github.com/AJMerr/hydianflow/internal/history"
+	"github.com/AJMerr/hydianflow/internal/realtime"
+	"github.com/AJMerr/hydianflow/internal/utils"
+	"gorm.io/gorm"
+)
+
+// TaskPullRequestRequest optionally sets the branch the pull request merges
+// into (the repository's default branch otherwise) and whether it starts as
+// a draft.
+type TaskPullRequestRequest struct {
+	Base  *string `json:"base,omitempty"`
+	Draft *bool   `json:"draft,omitempty"`
+}
+
+type TaskPullRequestResponse struct {
+	Number int          `json:"number"`
+	URL    string       `json:"url"`
+	Task   TaskResponse `json:"task"`
+}
+
+// pullRequestBody is the task description followed by a reference the
+// webhook handlers resolve back to the task.
+func pullRequestBody(t database.Task) string {
+	ref := fmt.Sprintf("task:#%d", t.ID)
+	if d := strings.TrimSpace(t.Description); d != "" {
+		return d + "\n\n" + ref
+	}
+	return ref
+}
+
+// POST /api/v1/tasks/{id}/pull-request
+//
+// Opens a pull request, a draft unless asked otherwise, from the task's
+// branch with the caller's token and links it to the task.
+func (h *Handler) CreatePullRequest(w http.ResponseWriter, r *http.Request) {
+	uid, ok := mustUserID(r)
+	if !ok {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "login required")
+		return
+	}
+
+	t, ok := h.loadTask(w, r, uid, database.ProjectRoleMember)
+	if !ok {
+		return
+	}
+	before := t
+
+	var req TaskPullRequestRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.Error(w, http.StatusBadRequest, "bad_json", "invalid JSON body")
+		return
+	}
+	if t.RepoName == nil || *t.RepoName == "" {
+		utils.Error(w, http.StatusBadRequest, "validation", "task has no repo_full_name")
+		return
+	}
+	if t.BranchHint == nil || *t.BranchHint == "" {
+		utils.Error(w, http.StatusBadRequest, "validation", "task has no branch; create one first")
+		return
+	}
+	if t.PRNumber != nil && t.PRState != nil && *t.PRState == "open" {
+		utils.Error(w, http.StatusConflict, "pull_request_exists", "task already has an open pull request")
+		return
+	}
+	base := ""
+	if req.Base != nil {
+		base = strings.TrimSpace(*req.Base)
+		if !validBranch(base) {
+			utils.Error(w, http.StatusBadRequest, "validation", "invalid base branch")
+			return
+		}
+	}
+	draft := true
+	if req.Draft != nil {
+		draft = *req.Draft
+	}
+
+	if h.GH == nil {
+		utils.Error(w, http.StatusServiceUnavailable, "github_unavailable", "GitHub integration is not configured")
+		return
+	}
+	pr, err := h.GH.CreatePullRequest(r.Context(), uid, *t.RepoName, *t.BranchHint, base, t.Title, pullRequestBody(t), draft)
+	if err != nil {
+		githubhttp.WriteError(w, err, func(msg string) (int, string) {
+			switch lower := strings.ToLower(msg); {
+			case strings.Contains(lower, "already exists"):
+				return http.StatusConflict, "pull_request_exists"
+			case strings.Contains(lower, "no commits between"):
+				return http.StatusUnprocessableEntity, "no_commits"
+			case strings.Contains(lower, "draft"):
+				// Draft PRs aren't available on every plan
+				return http.StatusUnprocessableEntity, "draft_unsupported"
+			}
+			return http.StatusUnprocessableEntity, "github_validation"
+		})
+		return
+	}
+
+	number := pr.GetNumber()
+	url := pr.GetHTMLURL()
+	state := "open"
+	t.PRNumber = &number
+	t.PRURL = &url
+	t.PRState = &state
+	t.PRDraft = pr.GetDraft()
+
+	if err := h.DB.Transaction(func(tx *gorm.DB) error {
+		// Only the PR columns: the task may have changed while GitHub was
+		// busy, not least through the pull_request webhook this triggers
+		if err := tx.Model(&database.Task{}).Where("id = ?", t.ID).Updates(map[string]any{
+			"pr_number":  t.PRNumber,
+			"pr_url":     t.PRURL,
+			"pr_state":   t.PRState,
+			"pr_draft":   t.PRDraft,
+			"updated_at": time.Now().UTC(),
+		}).Error; err != nil {
+			return err
+		}
+		if err := history.Record(tx, t.ID, history.UserActor(uid), history.Diff(before, t)...); err != nil {
+			return err
+		}
+		if err := tx.First(&t, t.ID).Error; err != nil {
+			return err
+		}
+		return realtime.PublishTask(tx, realtime.TaskUpdated, realtime.SourceUser, t)
+	}); err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_update", "pull request opened but the task could not be updated")
+		return
+	}
+
+	out, err := toResps(h.DB, []database.Task{t})
+	if err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_get", "could not load task progress")
+		return
+	}
+	utils.JSON(w, http.StatusCreated, TaskPullRequestResponse{Number: number, URL: url, Task: out[0]})
+}
@@ -4,16 +4,35 @@ import (
 	"net/http"
 
 	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/githubapi"
 	"github.com/go-chi/chi/v5"
 )
 
-func Router(db *database.DB) http.Handler {
-	h := &Handler{DB: db.DB}
+func Router(db *database.DB, gh *githubapi.Service, branchTemplate string) http.Handler {
+	h := &Handler{DB: db.DB, GH: gh, BranchTemplate: branchTemplate}
 
 	r := chi.NewRouter()
 	r.Post("/", h.Create)
 	r.Get("/", h.GetAll)
+	r.Get("/search", h.Search)
+	r.Get("/overdue", h.Overdue)
+	r.Post("/bulk", h.Bulk)
 	r.Get("/{id}", h.GetByID)
+	r.Get("/{id}/history", h.History)
+	r.Get("/{id}/comments", h.ListComments)
+	r.Post("/{id}/comments", h.CreateComment)
+	r.Patch("/{id}/comments/{commentID}", h.UpdateComment)
+	r.Delete("/{id}/comments/{commentID}", h.DeleteComment)
+	r.Post("/{id}/move", h.Move)
+	r.Post("/{id}/branch", h.CreateBranch)
+	r.Post("/{id}/pull-request", h.CreatePullRequest)
+	r.Get("/{id}/dependencies", h.ListDependencies)
+	r.Post("/{id}/dependencies", h.AddDependency)
+	r.Delete("/{id}/dependencies/{otherID}", h.RemoveDependency)
+	r.Get("/{id}/checklist", h.ListChecklist)
+	r.Post("/{id}/checklist", h.CreateChecklistItem)
+	r.Patch("/{id}/checklist/{itemID}", h.UpdateChecklistItem)
+	r.Delete("/{id}/checklist/{itemID}", h.DeleteChecklistItem)
 	r.Patch("/{id}", h.Update)
 	r.Delete("/{id}", h.Delete)
 
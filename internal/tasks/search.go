@@ -0,0 +1,170 @@
+package tasks
+
+import (
+	"encoding/base64"
+	"errors"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/AJMerr/hydianflow/internal/access"
+	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/utils"
+)
+
+// ts_headline marks matches with private-use sentinels rather than <mark>,
+// so the task text can be escaped before the tags are put in
+const (
+	hlStart      = "\uE000"
+	hlStop       = "\uE001"
+	headlineOpts = "StartSel=" + hlStart + ", StopSel=" + hlStop + ", MaxFragments=2, MaxWords=20, MinWords=5, FragmentDelimiter=\" … \""
+)
+
+var hlMarks = strings.NewReplacer(hlStart, "<mark>", hlStop, "</mark>")
+
+// highlight turns a ts_headline result into HTML that is safe to render:
+// the task text is escaped and only the match markers become <mark> tags.
+func highlight(s string) string {
+	return hlMarks.Replace(html.EscapeString(s))
+}
+
+type SearchResult struct {
+	TaskResponse
+	Rank           float32 `json:"rank"`
+	TitleHighlight string  `json:"title_highlight"`
+	Snippet        string  `json:"snippet"`
+}
+
+// GET /api/v1/tasks/search?q=&project_id=&tag=&assignee_id=&repo_full_name=&branch_hint=&cursor=
+func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
+	uid, ok := mustUserID(r)
+	if !ok {
+		utils.Error(w, http.StatusUnauthorized, "unauthorized", "login required")
+		return
+	}
+
+	qs := r.URL.Query()
+	text := strings.TrimSpace(qs.Get("q"))
+	if text == "" {
+		utils.Error(w, http.StatusBadRequest, "validation", "q is required")
+		return
+	}
+
+	where := access.Tasks(h.DB, uid)
+	if s := qs.Get("project_id"); s != "" {
+		pid, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			utils.Error(w, http.StatusBadRequest, "validation", "invalid project_id")
+			return
+		}
+		where = where.Where("tasks.project_id = ?", pid)
+	}
+	if s := strings.ToLower(strings.TrimSpace(qs.Get("tag"))); s != "" {
+		where = where.Where("tasks.tag = ?", s)
+	}
+	if s := qs.Get("assignee_id"); s != "" {
+		aid, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			utils.Error(w, http.StatusBadRequest, "validation", "invalid assignee_id")
+			return
+		}
+		where = where.Where("tasks.assignee_id = ?", aid)
+	}
+	if s := strings.TrimSpace(qs.Get("repo_full_name")); s != "" {
+		where = where.Where("tasks.repo_full_name = ?", s)
+	}
+	if s := strings.TrimSpace(qs.Get("branch_hint")); s != "" {
+		where = where.Where("tasks.branch_hint = ?", s)
+	}
+
+	const rank = "ts_rank(tasks.search_vector, websearch_to_tsquery('english', ?))"
+	where = where.Where("tasks.search_vector @@ websearch_to_tsquery('english', ?)", text)
+
+	if c := qs.Get("cursor"); c != "" {
+		rk, id, err := decodeSearchCursor(c)
+		if err != nil {
+			utils.Error(w, http.StatusBadRequest, "validation", "invalid cursor")
+			return
+		}
+		where = where.Where("("+rank+", tasks.id) < (?::real, ?)", text, rk, id)
+	}
+
+	limit := parseLimit(r, 20, 100)
+
+	type row struct {
+		database.Task
+		Rank           float32
+		TitleHighlight string
+		Snippet        string
+	}
+	var rows []row
+	if err := where.Model(&database.Task{}).
+		Select("tasks.*, "+rank+" AS rank, "+
+			"ts_headline('english', tasks.title, websearch_to_tsquery('english', ?), ?) AS title_highlight, "+
+			"ts_headline('english', coalesce(tasks.description, ''), websearch_to_tsquery('english', ?), ?) AS snippet",
+			text, text, headlineOpts, text, headlineOpts).
+		Order("rank DESC, tasks.id DESC").
+		Limit(limit).
+		Find(&rows).Error; err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_search", "could not search tasks")
+		return
+	}
+
+	tasks := make([]database.Task, len(rows))
+	for i := range rows {
+		tasks[i] = rows[i].Task
+	}
+	resps, err := toResps(h.DB, tasks)
+	if err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_get", "could not load task progress")
+		return
+	}
+	items := make([]SearchResult, len(rows))
+	for i, t := range rows {
+		items[i] = SearchResult{
+			TaskResponse:   resps[i],
+			Rank:           t.Rank,
+			TitleHighlight: highlight(t.TitleHighlight),
+			Snippet:        highlight(t.Snippet),
+		}
+	}
+	next := ""
+	if len(rows) == limit {
+		last := rows[len(rows)-1]
+		next = encodeSearchCursor(last.Rank, last.ID)
+	}
+
+	type listResp struct {
+		Items      []SearchResult `json:"items"`
+		NextCursor string         `json:"next_cursor"`
+	}
+	utils.JSON(w, http.StatusOK, listResp{Items: items, NextCursor: next})
+}
+
+// Search results are ordered by rank, so the cursor carries the last rank as
+// well as the id.
+func encodeSearchCursor(rank float32, id uint) string {
+	raw := strconv.FormatFloat(float64(rank), 'g', -1, 32) + "|" + strconv.FormatUint(uint64(id), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeSearchCursor(c string) (float32, uint, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(c)
+	if err != nil {
+		return 0, 0, err
+	}
+	rk, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return 0, 0, errors.New("malformed cursor")
+	}
+	f, err := strconv.ParseFloat(rk, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	n, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	return float32(f), uint(n), nil
+}
@@ -0,0 +1,58 @@
+package tasks
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestSearchCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		rank float32
+		id   uint
+	}{
+		{0, 1},
+		{0.0607927, 42},
+		{1e-20, 7},
+		{3.5, 1 << 31},
+	}
+	for _, tt := range tests {
+		rank, id, err := decodeSearchCursor(encodeSearchCursor(tt.rank, tt.id))
+		if err != nil || rank != tt.rank || id != tt.id {
+			t.Errorf("round trip %v/%d = %v/%d, %v", tt.rank, tt.id, rank, id, err)
+		}
+	}
+}
+
+func TestDecodeSearchCursorRejects(t *testing.T) {
+	enc := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	for _, c := range []string{
+		"%%%",
+		enc("0.5"),
+		enc("x|3"),
+		enc("0.5|-3"),
+		enc("0.5|"),
+	} {
+		if _, _, err := decodeSearchCursor(c); err == nil {
+			t.Errorf("cursor %q accepted", c)
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain title", "plain title"},
+		{hlStart + "deploy" + hlStop + " fix", "<mark>deploy</mark> fix"},
+		{
+			"<script>alert(1)</script> " + hlStart + "login" + hlStop,
+			"&lt;script&gt;alert(1)&lt;/script&gt; <mark>login</mark>",
+		},
+		{`<img src=x onerror="x">&`, "&lt;img src=x onerror=&#34;x&#34;&gt;&amp;"},
+	}
+	for _, tt := range tests {
+		if got := highlight(tt.in); got != tt.want {
+			t.Errorf("highlight(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
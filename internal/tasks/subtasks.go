@@ -0,0 +1,71 @@
+package tasks
+
+import (
+	"errors"
+
+	"github.com/AJMerr/hydianflow/internal/database"
+	"gorm.io/gorm"
+)
+
+var errBadParent = errors.New("invalid parent task")
+
+// checkParent validates parentID as the parent of t. The parent must live in
+// the same project as t (or be one of the same creator's personal tasks) and
+// must not be t itself or one of its descendants.
+func checkParent(db *gorm.DB, t database.Task, parentID uint) error {
+	if parentID == t.ID {
+		return errBadParent
+	}
+	var p database.Task
+	if err := db.Select("id, project_id, creator_id").First(&p, parentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errBadParent
+		}
+		return err
+	}
+	if !sameBoard(p, t) {
+		return errBadParent
+	}
+	if t.ID == 0 {
+		return nil
+	}
+
+	var cycle bool
+	if err := db.Raw(`
+		WITH RECURSIVE sub AS (
+			SELECT id FROM tasks WHERE parent_task_id = ? AND deleted_at IS NULL
+			UNION
+			SELECT c.id FROM tasks c JOIN sub ON c.parent_task_id = sub.id
+			WHERE c.deleted_at IS NULL
+		)
+		SELECT EXISTS (SELECT 1 FROM sub WHERE id = ?)`, t.ID, parentID).
+		Scan(&cycle).Error; err != nil {
+		return err
+	}
+	if cycle {
+		return errBadParent
+	}
+	return nil
+}
+
+// detachChildren un-nests a task's children, e.g. when it is deleted or
+// moves to another project.
+func detachChildren(tx *gorm.DB, id uint) error {
+	return tx.Model(&database.Task{}).
+		Where("parent_task_id = ?", id).
+		Update("parent_task_id", nil).Error
+}
+
+// parentsToCheck lists the tasks whose auto-completion may be affected by
+// a change from before to after.
+func parentsToCheck(before, after database.Task) []uint {
+	var ids []uint
+	if after.ParentTaskID != nil &&
+		(after.Status != before.Status || !sameID(before.ParentTaskID, after.ParentTaskID)) {
+		ids = append(ids, *after.ParentTaskID)
+	}
+	if after.AutoComplete && !before.AutoComplete {
+		ids = append(ids, after.ID)
+	}
+	return ids
+}
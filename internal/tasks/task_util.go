@@ -1,24 +1,44 @@
 package tasks
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/AJMerr/hydianflow/internal/access"
 	"github.com/AJMerr/hydianflow/internal/auth"
+	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/utils"
+	"github.com/AJMerr/hydianflow/internal/workflow"
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
 )
 
-var allowedStatuses = map[string]struct{}{
-	"todo": {}, "in_progress": {}, "done": {},
-}
-
+// normalStatus validates a status filter when no project workflow is known.
 func normalStatus(s string) (string, bool) {
 	s = strings.ToLower(strings.TrimSpace(s))
 	if s == "completed" {
 		s = "done"
 	}
-	_, ok := allowedStatuses[s]
-	return s, ok
+	return s, workflow.ValidKey(s)
+}
+
+func sameID(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// sameBoard reports whether two tasks live in the same project, or are both
+// personal tasks of the same creator.
+func sameBoard(a, b database.Task) bool {
+	if !sameID(a.ProjectID, b.ProjectID) {
+		return false
+	}
+	return a.ProjectID != nil || a.CreatorID == b.CreatorID
 }
 
 func mustUserID(r *http.Request) (uint, bool) {
@@ -26,6 +46,150 @@ func mustUserID(r *http.Request) (uint, bool) {
 	return uid, ok && uid != 0
 }
 
+// loadTask fetches the task named in the URL and checks the caller holds at
+// least min on it. On failure the error response has already been written.
+func (h *Handler) loadTask(w http.ResponseWriter, r *http.Request, uid uint, min database.ProjectRole) (database.Task, bool) {
+	var t database.Task
+	id64, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id64 == 0 {
+		utils.Error(w, http.StatusBadRequest, "bad_id", "invalid task id")
+		return t, false
+	}
+	if err := h.DB.First(&t, uint(id64)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.Error(w, http.StatusNotFound, "not_found", "task not found")
+			return t, false
+		}
+		utils.Error(w, http.StatusInternalServerError, "db_get", "could not load task")
+		return t, false
+	}
+	if _, err := access.RequireTask(h.DB, t, uid, min); err != nil {
+		access.WriteError(w, err, "task not found")
+		return t, false
+	}
+	return t, true
+}
+
+// normalTag validates a tag against the whitelist. An empty tag clears it.
+// Only personal tasks carry tags; project tasks use labels.
+func normalTag(s string) (*string, bool) {
+	tag := strings.ToLower(strings.TrimSpace(s))
+	switch tag {
+	case "":
+		return nil, true
+	case "feature", "feature_request", "issue":
+		return &tag, true
+	}
+	return nil, false
+}
+
+const msgProjectTag = "tags are only for personal tasks; project tasks use label_ids"
+
+// normalPriority validates a priority. An empty priority clears it.
+func normalPriority(s string) (*database.TaskPriority, bool) {
+	p := database.TaskPriority(strings.ToLower(strings.TrimSpace(s)))
+	switch p {
+	case "":
+		return nil, true
+	case database.TaskPriorityLow, database.TaskPriorityMedium, database.TaskPriorityHigh, database.TaskPriorityUrgent:
+		return &p, true
+	}
+	return nil, false
+}
+
+// parseDue parses an RFC3339 due date. An empty string clears it.
+func parseDue(s string) (*time.Time, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, true
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, false
+	}
+	t = t.UTC()
+	return &t, true
+}
+
+const maxEstimatePoints = 1000
+
+// normalEstimate validates story points. 0 clears the estimate.
+func normalEstimate(n int) (*int, bool) {
+	switch {
+	case n == 0:
+		return nil, true
+	case n < 0 || n > maxEstimatePoints:
+		return nil, false
+	}
+	return &n, true
+}
+
+// planningFields applies due_at, priority and estimate_points from a create
+// or update request. On failure it returns the validation message.
+func planningFields(t *database.Task, due, priority *string, estimate *int) string {
+	if due != nil {
+		v, ok := parseDue(*due)
+		if !ok {
+			return "invalid due_at (want RFC3339)"
+		}
+		t.DueAt = v
+	}
+	if priority != nil {
+		v, ok := normalPriority(*priority)
+		if !ok {
+			return "invalid priority"
+		}
+		t.Priority = v
+	}
+	if estimate != nil {
+		v, ok := normalEstimate(*estimate)
+		if !ok {
+			return "estimate_points must be between 1 and 1000"
+		}
+		t.EstimatePoints = v
+	}
+	return ""
+}
+
+// canUseProject checks the caller may put tasks into the project.
+func (h *Handler) canUseProject(w http.ResponseWriter, projectID, uid uint) bool {
+	if err := checkProject(h.DB, projectID, uid); err != nil {
+		if !writeTaskError(w, err) {
+			utils.Error(w, http.StatusInternalServerError, "db_access", "could not check access")
+		}
+		return false
+	}
+	return true
+}
+
+// checkProject is canUseProject for callers that report errors themselves.
+func checkProject(db *gorm.DB, projectID, uid uint) error {
+	_, _, err := access.Require(db, projectID, uid, database.ProjectRoleMember)
+	if errors.Is(err, access.ErrNotFound) || errors.Is(err, access.ErrForbidden) {
+		return taskError{status: http.StatusForbidden, code: "forbidden", message: "invalid project"}
+	}
+	return err
+}
+
+// checkAssignee makes sure t is only assigned to someone who can see it: a
+// member of its project, or the creator of a personal task.
+func checkAssignee(db *gorm.DB, t database.Task) error {
+	if t.AssigneeID == nil {
+		return nil
+	}
+	if t.ProjectID == nil {
+		if *t.AssigneeID != t.CreatorID {
+			return invalid("personal tasks can only be assigned to their creator")
+		}
+		return nil
+	}
+	_, err := access.RoleFor(db, *t.ProjectID, *t.AssigneeID)
+	if errors.Is(err, access.ErrNotFound) {
+		return invalid("assignee must be a member of the task's project")
+	}
+	return err
+}
+
 func parseLimit(r *http.Request, def, max int) int {
 	q := r.URL.Query().Get("limit")
 	if q == "" {
@@ -1,25 +1,132 @@
 package tasks
 
 import (
+	"net/http"
+
 	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/utils"
+	"github.com/AJMerr/hydianflow/internal/workflow"
+	"gorm.io/gorm"
 )
 
 func toResp(t database.Task) TaskResponse {
 	return TaskResponse{
-		ID:          t.ID,
-		Title:       t.Title,
-		Description: nullableStr(t.Description),
-		Tag:         t.Tag,
-		Status:      string(t.Status),
-		Position:    t.Position,
-		CreatorID:   t.CreatorID,
-		AssigneeID:  t.AssigneeID,
-		StartedAt:   t.StartedAt,
-		CompletedAt: t.CompletedAt,
-		CreatedAt:   t.CreatedAt,
-		UpdatedAt:   t.UpdatedAt,
-		RepoName:    t.RepoName,
-		BranchHint:  t.BranchHint,
-		ProjectID:   t.ProjectID,
+		ID:             t.ID,
+		Title:          t.Title,
+		Description:    nullableStr(t.Description),
+		Tag:            t.Tag,
+		Status:         string(t.Status),
+		Position:       t.Position,
+		CreatorID:      t.CreatorID,
+		AssigneeID:     t.AssigneeID,
+		StartedAt:      t.StartedAt,
+		CompletedAt:    t.CompletedAt,
+		CreatedAt:      t.CreatedAt,
+		UpdatedAt:      t.UpdatedAt,
+		RepoName:       t.RepoName,
+		BranchHint:     t.BranchHint,
+		PRNumber:       t.PRNumber,
+		PRURL:          t.PRURL,
+		PRState:        t.PRState,
+		PRDraft:        t.PRDraft,
+		IssueNumber:    t.IssueNumber,
+		ProjectID:      t.ProjectID,
+		ParentTaskID:   t.ParentTaskID,
+		AutoComplete:   t.AutoComplete,
+		DueAt:          t.DueAt,
+		Priority:       (*string)(t.Priority),
+		EstimatePoints: t.EstimatePoints,
+		TemplateID:     t.TemplateID,
+	}
+}
+
+// toResps converts a page of tasks and fills in labels, subtask and
+// checklist progress and open blockers for the ones that have any.
+func toResps(db *gorm.DB, rows []database.Task) ([]TaskResponse, error) {
+	out := make([]TaskResponse, len(rows))
+	if len(rows) == 0 {
+		return out, nil
+	}
+	ids := make([]uint, len(rows))
+	byID := make(map[uint]int, len(rows))
+	for i, t := range rows {
+		out[i] = toResp(t)
+		ids[i] = t.ID
+		byID[t.ID] = i
+	}
+
+	labels, err := loadLabels(db, ids)
+	if err != nil {
+		return nil, err
+	}
+	for id, ls := range labels {
+		out[byID[id]].Labels = ls
+	}
+
+	type childRow struct {
+		ParentTaskID uint
+		Status       string
+		N            int
+	}
+	var children []childRow
+	if err := db.Model(&database.Task{}).
+		Select("parent_task_id, status, COUNT(*) AS n").
+		Where("parent_task_id IN ?", ids).
+		Group("parent_task_id, status").
+		Scan(&children).Error; err != nil {
+		return nil, err
+	}
+	// Children share their parent's project, so its workflow decides what done means
+	workflows := workflow.NewCache(db)
+	for _, c := range children {
+		i := byID[c.ParentTaskID]
+		wf, err := workflows.Get(rows[i].ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		if out[i].Subtasks == nil {
+			out[i].Subtasks = &Progress{}
+		}
+		out[i].Subtasks.Total += c.N
+		if cat, _ := wf.Category(c.Status); cat == database.StatusCategoryDone {
+			out[i].Subtasks.Done += c.N
+		}
+	}
+
+	type checkRow struct {
+		TaskID uint
+		Done   int
+		Total  int
+	}
+	var checks []checkRow
+	if err := db.Model(&database.TaskChecklistItem{}).
+		Select("task_id, COUNT(*) FILTER (WHERE done) AS done, COUNT(*) AS total").
+		Where("task_id IN ?", ids).
+		Group("task_id").
+		Scan(&checks).Error; err != nil {
+		return nil, err
+	}
+	for _, c := range checks {
+		out[byID[c.TaskID]].Checklist = &Progress{Done: c.Done, Total: c.Total}
+	}
+
+	blockers, err := workflow.OpenBlockers(db, ids)
+	if err != nil {
+		return nil, err
+	}
+	for id, open := range blockers {
+		out[byID[id]].IsBlocked = true
+		out[byID[id]].BlockedBy = open
+	}
+	return out, nil
+}
+
+func (h *Handler) writeTask(w http.ResponseWriter, status int, t database.Task, warnings ...string) {
+	out, err := toResps(h.DB, []database.Task{t})
+	if err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_get", "could not load task progress")
+		return
 	}
+	out[0].Warnings = warnings
+	utils.JSON(w, status, out[0])
 }
@@ -2,14 +2,17 @@ package tasks
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
-	"strconv"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/history"
+	"github.com/AJMerr/hydianflow/internal/realtime"
 	"github.com/AJMerr/hydianflow/internal/utils"
-	"github.com/go-chi/chi/v5"
+	"github.com/AJMerr/hydianflow/internal/workflow"
 	"gorm.io/gorm"
 )
 
@@ -19,20 +22,9 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 		utils.Error(w, http.StatusUnauthorized, "unauthorized", "login required")
 		return
 	}
-	id64, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
-	if err != nil || id64 == 0 {
-		utils.Error(w, http.StatusBadRequest, "bad_id", "invalid task id")
-		return
-	}
-	id := uint(id64)
 
-	var t database.Task
-	if err := h.DB.Where("id = ? AND creator_id = ?", id, uid).First(&t).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			utils.Error(w, http.StatusNotFound, "not_found", "task not found")
-			return
-		}
-		utils.Error(w, http.StatusInternalServerError, "db_get", "could not load task")
+	t, ok := h.loadTask(w, r, uid, database.ProjectRoleMember)
+	if !ok {
 		return
 	}
 
@@ -44,7 +36,77 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	now := time.Now().UTC()
+	c, err := prepareUpdate(h.DB, workflow.NewCache(h.DB), uid, t, req, time.Now().UTC())
+	if err != nil {
+		if !writeTaskError(w, err) && !writeBlocked(w, err) {
+			utils.Error(w, http.StatusInternalServerError, "db_update", "could not update task")
+		}
+		return
+	}
+	var warnings []string
+	if c.warning != "" {
+		warnings = append(warnings, c.warning)
+	}
+
+	if err := h.DB.Transaction(func(tx *gorm.DB) error {
+		return c.save(tx, uid)
+	}); err != nil {
+		utils.Error(w, http.StatusInternalServerError, "db_update", "could not update task")
+		return
+	}
+	if warning := h.syncIssue(r.Context(), uid, c.before, c.after); warning != "" {
+		warnings = append(warnings, warning)
+	}
+	h.writeTask(w, http.StatusOK, c.after, warnings...)
+}
+
+// taskError is a validation failure on one task. Update writes it as the
+// response; Bulk reports it against the item.
+type taskError struct {
+	status  int
+	code    string
+	message string
+}
+
+func (e taskError) Error() string { return e.message }
+
+func invalid(msg string) error {
+	return taskError{status: http.StatusBadRequest, code: "validation", message: msg}
+}
+
+// writeTaskError writes err as the response. It reports whether err was a
+// taskError.
+func writeTaskError(w http.ResponseWriter, err error) bool {
+	var te taskError
+	if !errors.As(err, &te) {
+		return false
+	}
+	utils.Error(w, te.status, te.code, te.message)
+	return true
+}
+
+// taskChange is an update to one task that has been validated and is ready
+// to save.
+type taskChange struct {
+	before, after database.Task
+	// Labels belong to one project, so a moved task loses them unless new
+	// ones are given
+	labelIDs      []uint
+	replaceLabels bool
+	// Tag to turn into a label in the task's new project
+	tagLabel *string
+	// The task moved into a done status, so its dependents may be free
+	done    bool
+	warning string
+}
+
+// prepareUpdate applies req to t and validates the result against the
+// project the task ends up in. Problems with the request come back as a
+// taskError, a refused start as a blockedError; anything else is a database
+// error.
+func prepareUpdate(db *gorm.DB, workflows *workflow.Cache, uid uint, t database.Task, req TaskUpdateRequest, now time.Time) (taskChange, error) {
+	before := t
+
 	if req.Title != nil {
 		t.Title = *req.Title
 	}
@@ -55,24 +117,10 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 		t.Position = *req.Position
 	}
 	if req.AssigneeID != nil {
-		t.AssigneeID = req.AssigneeID
-	}
-	if req.Status != nil {
-		if s, ok := normalStatus(*req.Status); ok {
-			prev := t.Status
-			t.Status = database.TaskStatus(s)
-			if prev != "in_progress" && s == "in_progress" && t.StartedAt == nil {
-				t.StartedAt = &now
-			}
-			if s == "done" && t.CompletedAt == nil {
-				t.CompletedAt = &now
-			}
-			if s != "done" {
-				t.CompletedAt = nil
-			}
+		if *req.AssigneeID == 0 {
+			t.AssigneeID = nil
 		} else {
-			utils.Error(w, http.StatusBadRequest, "validation", "invalid status")
-			return
+			t.AssigneeID = req.AssigneeID
 		}
 	}
 	if req.RepoName != nil {
@@ -93,31 +141,150 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if req.ProjectID != nil {
+		if err := checkProject(db, *req.ProjectID, uid); err != nil {
+			return taskChange{}, err
+		}
 		t.ProjectID = req.ProjectID
+	}
+	movedProject := !sameID(before.ProjectID, t.ProjectID)
+
+	if err := checkAssignee(db, t); err != nil {
+		var te taskError
+		// A moved task drops an assignee who can't follow it, as it drops its
+		// parent and labels
+		if !errors.As(err, &te) || req.AssigneeID != nil || !movedProject {
+			return taskChange{}, err
+		}
+		t.AssigneeID = nil
+	}
 
-		var p database.Project
-		if err := h.DB.Where("id = ? AND owner_id = ?", *req.ProjectID, uid).First(&p).Error; err != nil {
-			utils.Error(w, http.StatusForbidden, "forbidden", "invalid project")
-			return
+	if req.AutoComplete != nil {
+		t.AutoComplete = *req.AutoComplete
+	}
+	if msg := planningFields(&t, req.DueAt, req.Priority, req.EstimatePoints); msg != "" {
+		return taskChange{}, invalid(msg)
+	}
+	if req.ParentTaskID != nil {
+		if *req.ParentTaskID == 0 {
+			t.ParentTaskID = nil
+		} else {
+			if err := checkParent(db, t, *req.ParentTaskID); err != nil {
+				if errors.Is(err, errBadParent) {
+					return taskChange{}, invalid("parent task not found, in another project, or a descendant of this task")
+				}
+				return taskChange{}, err
+			}
+			t.ParentTaskID = req.ParentTaskID
+		}
+	} else if movedProject {
+		// Subtasks stay within one project
+		t.ParentTaskID = nil
+	}
+
+	// Status is checked against the workflow of the project the task ends up in
+	wf, err := workflows.Get(t.ProjectID)
+	if err != nil {
+		return taskChange{}, err
+	}
+	from, err := workflows.Get(before.ProjectID)
+	if err != nil {
+		return taskChange{}, err
+	}
+	if req.Status != nil {
+		s, ok := wf.Normalize(*req.Status)
+		if !ok {
+			return taskChange{}, invalid("invalid status")
 		}
+		t.Status = database.TaskStatus(s)
+	} else if movedProject {
+		t.Status = database.TaskStatus(wf.Map(string(t.Status), from))
+	}
+	if t.Status != before.Status {
+		wf.Stamp(&t, now)
+	}
+
+	warning, err := checkStart(db, wf, before, t)
+	if err != nil {
+		return taskChange{}, err
 	}
 
 	if req.Tag != nil {
-		tag := strings.ToLower(strings.TrimSpace(*req.Tag))
-		switch tag {
-		case "":
-			t.Tag = nil
-		case "feature", "feature_request", "issue":
-			t.Tag = &tag
-		default:
-			utils.Error(w, http.StatusBadRequest, "validation", "invalid tag")
-			return
+		tag, ok := normalTag(*req.Tag)
+		if !ok {
+			return taskChange{}, invalid("invalid tag")
 		}
+		if tag != nil && t.ProjectID != nil {
+			return taskChange{}, invalid(msgProjectTag)
+		}
+		t.Tag = tag
+	}
+	// A personal task moving into a project takes its tag along as a label
+	var tagLabel *string
+	if movedProject && t.ProjectID != nil && t.Tag != nil {
+		tagLabel, t.Tag = t.Tag, nil
 	}
 
-	if err := h.DB.Save(&t).Error; err != nil {
-		utils.Error(w, http.StatusInternalServerError, "db_update", "could not update task")
-		return
+	var labelIDs []uint
+	if req.LabelIDs != nil {
+		labelIDs, err = checkLabels(db, t.ProjectID, *req.LabelIDs)
+		if err != nil {
+			if errors.Is(err, errBadLabels) {
+				return taskChange{}, invalid("labels must belong to the task's project")
+			}
+			return taskChange{}, err
+		}
+	}
+
+	return taskChange{
+		before:        before,
+		after:         t,
+		labelIDs:      labelIDs,
+		replaceLabels: req.LabelIDs != nil || movedProject,
+		tagLabel:      tagLabel,
+		done:          enteredDone(from, wf, before, t),
+		warning:       warning,
+	}, nil
+}
+
+// save writes the change along with its history, subtask and label
+// updates, parent completion and board events. Call it inside a transaction.
+func (c *taskChange) save(tx *gorm.DB, uid uint) error {
+	if err := tx.Save(&c.after).Error; err != nil {
+		return err
+	}
+	before, t := c.before, c.after
+	actor := history.UserActor(uid)
+	if err := history.Record(tx, t.ID, actor, history.Diff(before, t)...); err != nil {
+		return err
+	}
+	if !sameID(before.ProjectID, t.ProjectID) {
+		if err := detachChildren(tx, t.ID); err != nil {
+			return err
+		}
+	}
+	if c.replaceLabels {
+		ids := c.labelIDs
+		if c.tagLabel != nil {
+			id, err := labelForTag(tx, *t.ProjectID, *c.tagLabel)
+			if err != nil {
+				return err
+			}
+			if !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+		if err := setLabels(tx, t.ID, actor, ids); err != nil {
+			return err
+		}
+	}
+	if err := finishChange(tx, actor, before, t, c.done); err != nil {
+		return err
+	}
+	// A task moved to another project leaves its old board
+	if before.ProjectID != nil && (t.ProjectID == nil || *t.ProjectID != *before.ProjectID) {
+		if err := realtime.PublishTask(tx, realtime.TaskDeleted, realtime.SourceUser, before); err != nil {
+			return err
+		}
 	}
-	utils.JSON(w, http.StatusOK, toResp(t))
+	return realtime.PublishTask(tx, realtime.TaskUpdated, realtime.SourceUser, t)
 }
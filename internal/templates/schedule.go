@@ -0,0 +1,166 @@
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Named rules fire at midnight in the template's timezone. Weekly runs on
+// Mondays, since that's when teams plan their week.
+var namedRules = map[string]string{
+	"daily":   "0 0 * * *",
+	"weekly":  "0 0 * * 1",
+	"monthly": "0 0 1 * *",
+}
+
+// Schedule is a parsed recurrence rule: the five cron fields minute, hour,
+// day of month, month and day of week, each a set of allowed values.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// Cron matches either day field when both are restricted
+	domAny, dowAny bool
+}
+
+type field struct {
+	min, max int
+}
+
+var fields = [5]field{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+
+// Parse accepts "daily", "weekly", "monthly" (optionally prefixed with "@")
+// or a five-field cron expression supporting *, lists, ranges and steps.
+func Parse(rule string) (Schedule, error) {
+	rule = strings.ToLower(strings.TrimSpace(rule))
+	if named, ok := namedRules[strings.TrimPrefix(rule, "@")]; ok {
+		rule = named
+	}
+	parts := strings.Fields(rule)
+	if len(parts) != 5 {
+		return Schedule{}, errors.New("recurrence must be daily, weekly, monthly or a five-field cron expression")
+	}
+
+	var sets [5]uint64
+	for i, p := range parts {
+		set, err := parseField(p, fields[i])
+		if err != nil {
+			return Schedule{}, fmt.Errorf("cron field %d: %w", i+1, err)
+		}
+		sets[i] = set
+	}
+	// 7 is another name for Sunday
+	if sets[4]&(1<<7) != 0 {
+		sets[4] = sets[4]&^(1<<7) | 1
+	}
+	return Schedule{
+		minute: sets[0],
+		hour:   sets[1],
+		dom:    sets[2],
+		month:  sets[3],
+		dow:    sets[4],
+		domAny: parts[2] == "*",
+		dowAny: parts[4] == "*",
+	}, nil
+}
+
+func parseField(s string, f field) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(s, ",") {
+		step := 1
+		if base, st, ok := strings.Cut(part, "/"); ok {
+			n, err := strconv.Atoi(st)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step %q", st)
+			}
+			part, step = base, n
+		}
+
+		lo, hi := f.min, f.max
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			a, b, _ := strings.Cut(part, "-")
+			var err1, err2 error
+			lo, err1 = strconv.Atoi(a)
+			hi, err2 = strconv.Atoi(b)
+			if err1 != nil || err2 != nil {
+				return 0, fmt.Errorf("invalid range %q", part)
+			}
+		default:
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q", part)
+			}
+			lo = n
+			// "5/15" means every 15 starting at 5
+			if step == 1 {
+				hi = n
+			}
+		}
+		if lo < f.min || hi > f.max || lo > hi {
+			return 0, fmt.Errorf("%q is out of range %d-%d", part, f.min, f.max)
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+// Next returns the first time strictly after t at which the schedule fires,
+// evaluated in loc. It gives up after five years, which only happens for
+// rules like "0 0 31 2 *" that never match.
+func (s Schedule) Next(t time.Time, loc *time.Location) (time.Time, bool) {
+	t = t.In(loc).Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<int(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hour&(1<<t.Hour()) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minute&(1<<t.Minute()) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+func (s Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<t.Day()) != 0
+	dow := s.dow&(1<<int(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// NextRun parses rule and returns its next run after t in the named
+// timezone.
+func NextRun(rule, timezone string, t time.Time) (time.Time, error) {
+	sched, err := Parse(rule)
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unknown timezone %q", timezone)
+	}
+	next, ok := sched.Next(t, loc)
+	if !ok {
+		return time.Time{}, errors.New("recurrence never fires")
+	}
+	return next.UTC(), nil
+}
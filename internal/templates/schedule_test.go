@@ -0,0 +1,122 @@
+package templates
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		rule    string
+		wantErr bool
+	}{
+		{"daily", false},
+		{"@weekly", false},
+		{" MONTHLY ", false},
+		{"*/15 9-17 * * 1-5", false},
+		{"0,30 * 1-7 */2 7", false},
+		{"5/20 * * * *", false},
+		{"", true},
+		{"hourly", true},
+		{"* * * *", true},
+		{"* * * * * *", true},
+		{"60 * * * *", true},
+		{"* 24 * * *", true},
+		{"0 0 0 * *", true},
+		{"0 0 * 13 *", true},
+		{"0 0 * * 8", true},
+		{"*/0 * * * *", true},
+		{"5-1 * * * *", true},
+		{"a * * * *", true},
+		{"1-x * * * *", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			_, err := Parse(tt.rule)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse(%q) err = %v, wantErr %v", tt.rule, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("no tzdata: %v", err)
+	}
+	// A Saturday
+	from := time.Date(2026, 3, 14, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		rule string
+		from time.Time
+		loc  *time.Location
+		want time.Time
+	}{
+		{"daily", "daily", from, time.UTC, time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"weekly is monday", "weekly", from, time.UTC, time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{"monthly", "monthly", from, time.UTC, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"step", "*/15 * * * *", from, time.UTC, time.Date(2026, 3, 14, 10, 15, 0, 0, time.UTC)},
+		{"strictly after", "*/15 * * * *", time.Date(2026, 3, 14, 10, 15, 0, 0, time.UTC), time.UTC, time.Date(2026, 3, 14, 10, 30, 0, 0, time.UTC)},
+		{"step from offset", "5/20 * * * *", from, time.UTC, time.Date(2026, 3, 14, 10, 25, 0, 0, time.UTC)},
+		{"7 is sunday", "0 0 * * 7", from, time.UTC, time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"either day field", "0 0 13 * 5", from, time.UTC, time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)},
+		{"day of week only", "0 0 * * 5", from, time.UTC, time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)},
+		{"year rollover", "0 0 1 1 *", from, time.UTC, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"in timezone", "0 9 * * 1-5", from, ny, time.Date(2026, 3, 16, 9, 0, 0, 0, ny)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.rule, err)
+			}
+			got, ok := s.Next(tt.from, tt.loc)
+			if !ok || !got.Equal(tt.want) {
+				t.Errorf("Next = %v, %v; want %v", got, ok, tt.want)
+			}
+		})
+	}
+
+	s, err := Parse("0 0 31 2 *")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got, ok := s.Next(from, time.UTC); ok {
+		t.Errorf("Feb 31 fired at %v", got)
+	}
+}
+
+func TestNextRun(t *testing.T) {
+	from := time.Date(2026, 3, 14, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		rule, tz string
+		want     time.Time
+		wantErr  bool
+	}{
+		{"utc", "daily", "UTC", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC), false},
+		// Midnight in New York during daylight saving time
+		{"converted to utc", "daily", "America/New_York", time.Date(2026, 3, 15, 4, 0, 0, 0, time.UTC), false},
+		{"bad rule", "hourly", "UTC", time.Time{}, true},
+		{"bad timezone", "daily", "Mars/Olympus_Mons", time.Time{}, true},
+		{"never fires", "0 0 30 2 *", "UTC", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NextRun(tt.rule, tt.tz, from)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("NextRun = %v, want %v", got, tt.want)
+			}
+			if err == nil && got.Location() != time.UTC {
+				t.Errorf("NextRun location = %v, want UTC", got.Location())
+			}
+		})
+	}
+}
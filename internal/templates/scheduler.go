@@ -0,0 +1,173 @@
+package templates
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/history"
+	"github.com/AJMerr/hydianflow/internal/realtime"
+	"github.com/AJMerr/hydianflow/internal/workflow"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Advisory lock key held by whichever replica is materializing templates
+const schedulerLockKey int64 = 0x68665f746d706c // "hf_tmpl"
+
+// Scheduler creates tasks from due templates. Each pass runs under a
+// transaction-scoped advisory lock, so only one replica does the work; the
+// unique (template_id, template_run_at) index keeps a run from being
+// materialized twice even if the lock is lost.
+type Scheduler struct {
+	DB        *gorm.DB
+	Interval  time.Duration
+	BatchSize int
+}
+
+func NewScheduler(db *database.DB) *Scheduler {
+	return &Scheduler{
+		DB:        db.DB,
+		Interval:  30 * time.Second,
+		BatchSize: 50,
+	}
+}
+
+// Run materializes templates until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := s.RunOnce(time.Now().UTC())
+		if err != nil {
+			log.Printf("template scheduler: %v", err)
+		}
+		if err == nil && n == s.BatchSize {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce handles one batch of templates due at now, returning how many
+// were processed. It returns 0 without error if another replica holds the
+// lock.
+func (s *Scheduler) RunOnce(now time.Time) (int, error) {
+	var n int
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var locked bool
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", schedulerLockKey).Scan(&locked).Error; err != nil {
+			return err
+		}
+		if !locked {
+			return nil
+		}
+
+		// Projects are soft-deleted, so their templates outlive them
+		var due []database.TaskTemplate
+		if err := tx.Select("task_templates.*").
+			Joins("JOIN projects p ON p.id = task_templates.project_id AND p.deleted_at IS NULL").
+			Where("task_templates.enabled AND task_templates.next_run_at <= ?", now).
+			Order("task_templates.next_run_at").
+			Limit(s.BatchSize).
+			Find(&due).Error; err != nil {
+			return err
+		}
+		for _, tpl := range due {
+			if err := materialize(tx, tpl, now); err != nil {
+				return err
+			}
+		}
+		n = len(due)
+		return nil
+	})
+	return n, err
+}
+
+// materialize creates the task for tpl's current run and schedules the next
+// one. Runs missed while no server was up collapse into a single task.
+func materialize(tx *gorm.DB, tpl database.TaskTemplate, now time.Time) error {
+	runAt := tpl.NextRunAt
+	next, err := NextRun(tpl.Recurrence, tpl.Timezone, now)
+	if err != nil {
+		// The rule was valid when saved; a timezone database change is the
+		// likely cause. Stop the template rather than retrying forever.
+		log.Printf("template scheduler: disabling template %d: %v", tpl.ID, err)
+		return tx.Model(&database.TaskTemplate{}).
+			Where("id = ?", tpl.ID).
+			Update("enabled", false).Error
+	}
+
+	wf, err := workflow.ForProject(tx, &tpl.ProjectID)
+	if err != nil {
+		return err
+	}
+	status := wf.First(database.StatusCategoryTodo)
+
+	var maxPos float64
+	if err := tx.Model(&database.Task{}).
+		Where("project_id = ? AND status = ?", tpl.ProjectID, status).
+		Select("COALESCE(MAX(position), 0)").
+		Scan(&maxPos).Error; err != nil {
+		return err
+	}
+
+	projectID := tpl.ProjectID
+	templateID := tpl.ID
+	t := database.Task{
+		Title:          tpl.Title,
+		Description:    tpl.Description,
+		Status:         database.TaskStatus(status),
+		Position:       maxPos + 1000,
+		CreatorID:      tpl.CreatorID,
+		AssigneeID:     tpl.AssigneeID,
+		ProjectID:      &projectID,
+		Priority:       tpl.Priority,
+		EstimatePoints: tpl.EstimatePoints,
+		TemplateID:     &templateID,
+		TemplateRunAt:  &runAt,
+	}
+	if tpl.DueInDays != nil {
+		due := runAt.AddDate(0, 0, *tpl.DueInDays)
+		t.DueAt = &due
+	}
+	wf.Stamp(&t, now)
+
+	res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&t)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected > 0 {
+		// Labels deleted since the template was saved are skipped
+		if len(tpl.LabelIDs) > 0 {
+			if err := tx.Exec(`
+				INSERT INTO task_labels (task_id, label_id)
+				SELECT ?, id FROM labels WHERE id IN ? AND project_id = ?
+				ON CONFLICT DO NOTHING`, t.ID, tpl.LabelIDs, tpl.ProjectID).Error; err != nil {
+				return err
+			}
+		}
+		if err := history.Record(tx, t.ID, history.UserActor(tpl.CreatorID), history.Change{
+			Field:    history.FieldCreated,
+			NewValue: history.Str(t.Title),
+		}); err != nil {
+			return err
+		}
+		if err := realtime.PublishTask(tx, realtime.TaskCreated, realtime.SourceScheduler, t); err != nil {
+			return err
+		}
+	}
+
+	return tx.Model(&database.TaskTemplate{}).
+		Where("id = ?", tpl.ID).
+		Updates(map[string]any{"next_run_at": next, "last_run_at": runAt}).Error
+}
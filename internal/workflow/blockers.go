@@ -0,0 +1,98 @@
+package workflow
+
+import (
+	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/history"
+	"github.com/AJMerr/hydianflow/internal/realtime"
+	"gorm.io/gorm"
+)
+
+// OpenBlockers returns, for each of the given tasks that is blocked, the ids
+// of its blockers that are not yet in a done status.
+func OpenBlockers(db *gorm.DB, taskIDs []uint) (map[uint][]uint, error) {
+	out := make(map[uint][]uint)
+	if len(taskIDs) == 0 {
+		return out, nil
+	}
+	type row struct {
+		BlockedID uint
+		BlockerID uint
+		Status    string
+		ProjectID *uint
+	}
+	var rows []row
+	if err := db.Table("task_dependencies d").
+		Select("d.blocked_id, d.blocker_id, b.status, b.project_id").
+		Joins("JOIN tasks b ON b.id = d.blocker_id AND b.deleted_at IS NULL").
+		Where("d.blocked_id IN ?", taskIDs).
+		Order("d.blocked_id, d.blocker_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	workflows := NewCache(db)
+	for _, r := range rows {
+		wf, err := workflows.Get(r.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		if cat, _ := wf.Category(r.Status); cat != database.StatusCategoryDone {
+			out[r.BlockedID] = append(out[r.BlockedID], r.BlockerID)
+		}
+	}
+	return out, nil
+}
+
+// Unblocked returns the tasks blocked by any of completedIDs that have no
+// open blockers left. Call it after the completed tasks have been saved.
+func Unblocked(db *gorm.DB, completedIDs []uint) ([]uint, error) {
+	if len(completedIDs) == 0 {
+		return nil, nil
+	}
+	var candidates []uint
+	if err := db.Table("task_dependencies d").
+		Joins("JOIN tasks t ON t.id = d.blocked_id AND t.deleted_at IS NULL").
+		Where("d.blocker_id IN ?", completedIDs).
+		Distinct().
+		Order("d.blocked_id").
+		Pluck("d.blocked_id", &candidates).Error; err != nil {
+		return nil, err
+	}
+	open, err := OpenBlockers(db, candidates)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]uint, 0, len(candidates))
+	for _, id := range candidates {
+		if len(open[id]) == 0 {
+			out = append(out, id)
+		}
+	}
+	return out, nil
+}
+
+// ReleaseDependents finds the tasks left without open blockers once
+// completedIDs are done, records that in their history and publishes them to
+// their boards. Call it inside the transaction that completed the tasks.
+func ReleaseDependents(tx *gorm.DB, actor history.Actor, source string, completedIDs []uint) ([]uint, error) {
+	unblocked, err := Unblocked(tx, completedIDs)
+	if err != nil || len(unblocked) == 0 {
+		return unblocked, err
+	}
+	if err := history.RecordEach(tx, unblocked, actor, history.Change{
+		Field:    history.FieldBlocked,
+		OldValue: history.Str("true"),
+		NewValue: history.Str("false"),
+	}); err != nil {
+		return nil, err
+	}
+	var dependents []database.Task
+	if err := tx.Select("id, project_id, status").Where("id IN ?", unblocked).Find(&dependents).Error; err != nil {
+		return nil, err
+	}
+	for _, d := range dependents {
+		if err := realtime.PublishTask(tx, realtime.TaskUpdated, source, d); err != nil {
+			return nil, err
+		}
+	}
+	return unblocked, nil
+}
@@ -0,0 +1,91 @@
+package workflow
+
+import (
+	"errors"
+	"time"
+
+	"github.com/AJMerr/hydianflow/internal/database"
+	"github.com/AJMerr/hydianflow/internal/history"
+	"github.com/AJMerr/hydianflow/internal/realtime"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CompleteParents moves each auto-completing parent to done once all of its
+// children are done, then repeats for that parent's own parent. It returns
+// the parents it completed. Call it inside the transaction that changed the
+// children's statuses.
+func CompleteParents(tx *gorm.DB, actor history.Actor, source string, parentIDs ...uint) ([]uint, error) {
+	workflows := NewCache(tx)
+	now := time.Now().UTC()
+	seen := make(map[uint]bool, len(parentIDs))
+	var completed []uint
+
+	queue := append([]uint(nil), parentIDs...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		var p database.Task
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND auto_complete", id).
+			Take(&p).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		wf, err := workflows.Get(p.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		if cat, _ := wf.Category(string(p.Status)); cat == database.StatusCategoryDone {
+			continue
+		}
+
+		var statuses []string
+		if err := tx.Model(&database.Task{}).
+			Where("parent_task_id = ?", p.ID).
+			Distinct().
+			Pluck("status", &statuses).Error; err != nil {
+			return nil, err
+		}
+		if len(statuses) == 0 {
+			continue
+		}
+		allDone := true
+		for _, s := range statuses {
+			if cat, _ := wf.Category(s); cat != database.StatusCategoryDone {
+				allDone = false
+				break
+			}
+		}
+		if !allDone {
+			continue
+		}
+
+		before := p
+		p.Status = database.TaskStatus(wf.First(database.StatusCategoryDone))
+		wf.Stamp(&p, now)
+		if err := tx.Save(&p).Error; err != nil {
+			return nil, err
+		}
+		if err := history.Record(tx, p.ID, actor, history.Diff(before, p)...); err != nil {
+			return nil, err
+		}
+		if err := realtime.PublishTask(tx, realtime.TaskUpdated, source, p); err != nil {
+			return nil, err
+		}
+		completed = append(completed, p.ID)
+		if p.ParentTaskID != nil {
+			queue = append(queue, *p.ParentTaskID)
+		}
+	}
+	return completed, nil
+}
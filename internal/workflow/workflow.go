@@ -0,0 +1,165 @@
+package workflow
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/AJMerr/hydianflow/internal/database"
+	"gorm.io/gorm"
+)
+
+var keyRe = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+// Status is one column of a project board.
+type Status struct {
+	Key      string                  `json:"key"`
+	Label    string                  `json:"label"`
+	Category database.StatusCategory `json:"category"`
+}
+
+// Workflow is the ordered list of statuses a project's tasks move through.
+type Workflow struct {
+	Statuses []Status `json:"statuses"`
+	// Custom is false when the project uses the built-in workflow
+	Custom bool `json:"custom"`
+}
+
+// Default is used for personal tasks and projects without custom statuses.
+func Default() Workflow {
+	return Workflow{Statuses: []Status{
+		{Key: "todo", Label: "To Do", Category: database.StatusCategoryTodo},
+		{Key: "in_progress", Label: "In Progress", Category: database.StatusCategoryDoing},
+		{Key: "done", Label: "Done", Category: database.StatusCategoryDone},
+	}}
+}
+
+// ForProject loads a project's workflow. A nil project gets the default.
+func ForProject(db *gorm.DB, projectID *uint) (Workflow, error) {
+	if projectID == nil {
+		return Default(), nil
+	}
+	var rows []database.ProjectStatus
+	if err := db.Where("project_id = ?", *projectID).
+		Order("position ASC, id ASC").
+		Find(&rows).Error; err != nil {
+		return Workflow{}, err
+	}
+	if len(rows) == 0 {
+		return Default(), nil
+	}
+	wf := Workflow{Statuses: make([]Status, len(rows)), Custom: true}
+	for i, r := range rows {
+		wf.Statuses[i] = Status{Key: r.Key, Label: r.Label, Category: r.Category}
+	}
+	return wf, nil
+}
+
+// Cache memoizes workflows by project for code touching many tasks at once.
+type Cache struct {
+	db   *gorm.DB
+	byID map[uint]Workflow
+}
+
+func NewCache(db *gorm.DB) *Cache {
+	return &Cache{db: db, byID: make(map[uint]Workflow)}
+}
+
+func (c *Cache) Get(projectID *uint) (Workflow, error) {
+	if projectID == nil {
+		return Default(), nil
+	}
+	if wf, ok := c.byID[*projectID]; ok {
+		return wf, nil
+	}
+	wf, err := ForProject(c.db, projectID)
+	if err != nil {
+		return Workflow{}, err
+	}
+	c.byID[*projectID] = wf
+	return wf, nil
+}
+
+// Normalize lower-cases and validates a status key. "completed" is accepted
+// as an alias for the workflow's first done status.
+func (wf Workflow) Normalize(s string) (string, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if _, ok := wf.Category(s); ok {
+		return s, true
+	}
+	if s == "completed" {
+		if k := wf.First(database.StatusCategoryDone); k != "" {
+			return k, true
+		}
+	}
+	return s, false
+}
+
+// Category reports the category of a status key.
+func (wf Workflow) Category(key string) (database.StatusCategory, bool) {
+	for _, s := range wf.Statuses {
+		if s.Key == key {
+			return s.Category, true
+		}
+	}
+	return "", false
+}
+
+// First returns the first status key in a category, or "" if there is none.
+func (wf Workflow) First(cat database.StatusCategory) string {
+	for _, s := range wf.Statuses {
+		if s.Category == cat {
+			return s.Key
+		}
+	}
+	return ""
+}
+
+// Map finds the equivalent of a status from another workflow: the same key
+// if it exists, otherwise the first status in the same category.
+func (wf Workflow) Map(key string, from Workflow) string {
+	if _, ok := wf.Category(key); ok {
+		return key
+	}
+	cat, ok := from.Category(key)
+	if !ok {
+		cat = database.StatusCategoryTodo
+	}
+	if k := wf.First(cat); k != "" {
+		return k
+	}
+	return wf.First(database.StatusCategoryTodo)
+}
+
+// Stamp keeps StartedAt/CompletedAt in line with the category of the task's
+// current status.
+func (wf Workflow) Stamp(t *database.Task, now time.Time) {
+	cat, _ := wf.Category(string(t.Status))
+	switch cat {
+	case database.StatusCategoryDoing:
+		if t.StartedAt == nil {
+			t.StartedAt = &now
+		}
+	case database.StatusCategoryDone:
+		if t.CompletedAt == nil {
+			t.CompletedAt = &now
+		}
+	}
+	if cat != database.StatusCategoryDone {
+		t.CompletedAt = nil
+	}
+}
+
+// ValidKey reports whether s is usable as a status key.
+func ValidKey(s string) bool {
+	return keyRe.MatchString(s)
+}
+
+// ValidCategory reports whether c is a known category.
+func ValidCategory(c database.StatusCategory) bool {
+	switch c {
+	case database.StatusCategoryTodo, database.StatusCategoryDoing, database.StatusCategoryDone:
+		return true
+	}
+	return false
+}
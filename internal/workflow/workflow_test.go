@@ -0,0 +1,115 @@
+package workflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AJMerr/hydianflow/internal/database"
+)
+
+func custom() Workflow {
+	return Workflow{Custom: true, Statuses: []Status{
+		{Key: "backlog", Category: database.StatusCategoryTodo},
+		{Key: "todo", Category: database.StatusCategoryTodo},
+		{Key: "review", Category: database.StatusCategoryDoing},
+		{Key: "building", Category: database.StatusCategoryDoing},
+		{Key: "shipped", Category: database.StatusCategoryDone},
+	}}
+}
+
+// noDoing has no status to start work in.
+func noDoing() Workflow {
+	return Workflow{Custom: true, Statuses: []Status{
+		{Key: "open", Category: database.StatusCategoryTodo},
+		{Key: "closed", Category: database.StatusCategoryDone},
+	}}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name   string
+		wf     Workflow
+		in     string
+		want   string
+		wantOK bool
+	}{
+		{"exact key", Default(), "in_progress", "in_progress", true},
+		{"case and space", Default(), "  Done ", "done", true},
+		{"completed alias", Default(), "completed", "done", true},
+		{"completed alias custom", custom(), "COMPLETED", "shipped", true},
+		{"unknown", Default(), "review", "review", false},
+		{"empty", custom(), "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.wf.Normalize(tt.in)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Normalize(%q) = %q, %v; want %q, %v", tt.in, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestMap(t *testing.T) {
+	tests := []struct {
+		name     string
+		to, from Workflow
+		key      string
+		want     string
+	}{
+		{"same key kept", custom(), Default(), "todo", "todo"},
+		{"todo category", Default(), custom(), "backlog", "todo"},
+		{"doing category", Default(), custom(), "building", "in_progress"},
+		{"first of category", custom(), Default(), "in_progress", "review"},
+		{"done category", custom(), Default(), "done", "shipped"},
+		{"unknown key goes to todo", custom(), Default(), "gone", "backlog"},
+		{"missing category goes to todo", noDoing(), Default(), "in_progress", "open"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.to.Map(tt.key, tt.from); got != tt.want {
+				t.Errorf("Map(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStamp(t *testing.T) {
+	now := time.Date(2026, 3, 14, 10, 0, 0, 0, time.UTC)
+	earlier := now.Add(-48 * time.Hour)
+
+	tests := []struct {
+		name          string
+		status        string
+		started, done *time.Time
+		wantStarted   *time.Time
+		wantDone      *time.Time
+	}{
+		{"todo clears completed", "backlog", &earlier, &earlier, &earlier, nil},
+		{"doing starts", "review", nil, nil, &now, nil},
+		{"doing keeps start", "building", &earlier, nil, &earlier, nil},
+		{"doing reopens", "review", &earlier, &earlier, &earlier, nil},
+		{"done completes", "shipped", nil, nil, nil, &now},
+		{"done keeps completion", "shipped", &earlier, &earlier, &earlier, &earlier},
+		{"unknown status reopens", "gone", nil, &earlier, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := database.Task{Status: database.TaskStatus(tt.status), StartedAt: tt.started, CompletedAt: tt.done}
+			custom().Stamp(&task, now)
+			if !sameTime(task.StartedAt, tt.wantStarted) {
+				t.Errorf("StartedAt = %v, want %v", task.StartedAt, tt.wantStarted)
+			}
+			if !sameTime(task.CompletedAt, tt.wantDone) {
+				t.Errorf("CompletedAt = %v, want %v", task.CompletedAt, tt.wantDone)
+			}
+		})
+	}
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
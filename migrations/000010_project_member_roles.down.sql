@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_project_members_user;
+
+ALTER TABLE project_members
+  DROP CONSTRAINT IF EXISTS project_members_role_check;
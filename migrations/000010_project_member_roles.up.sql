@@ -0,0 +1,13 @@
+-- Every project owner gets an explicit membership row
+INSERT INTO project_members (project_id, user_id, role)
+SELECT id, owner_id, 'owner'
+FROM projects
+WHERE deleted_at IS NULL
+ON CONFLICT (project_id, user_id) DO UPDATE SET role = 'owner';
+
+ALTER TABLE project_members
+  ADD CONSTRAINT project_members_role_check
+    CHECK (role IN ('owner','admin','member','viewer'));
+
+CREATE INDEX IF NOT EXISTS idx_project_members_user
+  ON project_members (user_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_project_invitations_login_pending;
+DROP INDEX IF EXISTS uq_project_invitations_pending;
+DROP INDEX IF EXISTS idx_project_invitations_project;
+DROP TABLE IF EXISTS project_invitations;
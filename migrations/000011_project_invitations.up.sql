@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS project_invitations (
+  id              BIGSERIAL PRIMARY KEY,
+  created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+  deleted_at      TIMESTAMPTZ,
+
+  project_id      BIGINT NOT NULL REFERENCES projects(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  github_login    TEXT   NOT NULL,
+  role            TEXT   NOT NULL DEFAULT 'member' CHECK (role IN ('admin','member','viewer')),
+  token           TEXT   NOT NULL UNIQUE,
+  invited_by_id   BIGINT NOT NULL REFERENCES users(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  expires_at      TIMESTAMPTZ NOT NULL,
+  accepted_at     TIMESTAMPTZ,
+  accepted_by_id  BIGINT REFERENCES users(id) ON UPDATE CASCADE ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_invitations_project
+  ON project_invitations (project_id);
+
+-- One open invitation per login per project
+CREATE UNIQUE INDEX IF NOT EXISTS uq_project_invitations_pending
+  ON project_invitations (project_id, lower(github_login))
+  WHERE accepted_at IS NULL AND deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_project_invitations_login_pending
+  ON project_invitations (lower(github_login))
+  WHERE accepted_at IS NULL AND deleted_at IS NULL;
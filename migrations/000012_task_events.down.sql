@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_task_events_delivery;
+DROP INDEX IF EXISTS idx_task_events_task_created;
+DROP TABLE IF EXISTS task_events;
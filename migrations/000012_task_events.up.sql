@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS task_events (
+  id                 BIGSERIAL PRIMARY KEY,
+  created_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  task_id            BIGINT NOT NULL REFERENCES tasks(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  -- Who made the change: a user, or a GitHub webhook delivery
+  actor_user_id      BIGINT REFERENCES users(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  actor_delivery_id  TEXT,
+
+  field              TEXT NOT NULL,
+  old_value          TEXT,
+  new_value          TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_events_task_created
+  ON task_events (task_id, created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_task_events_delivery
+  ON task_events (actor_delivery_id)
+  WHERE actor_delivery_id IS NOT NULL;
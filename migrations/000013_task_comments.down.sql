@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS task_comment_mentions;
+DROP INDEX IF EXISTS idx_task_comments_task;
+DROP TABLE IF EXISTS task_comments;
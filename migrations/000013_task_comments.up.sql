@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS task_comments (
+  id          BIGSERIAL PRIMARY KEY,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  deleted_at  TIMESTAMPTZ,
+
+  task_id     BIGINT NOT NULL REFERENCES tasks(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  author_id   BIGINT NOT NULL REFERENCES users(id) ON UPDATE CASCADE ON DELETE RESTRICT,
+  body        TEXT   NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_comments_task
+  ON task_comments (task_id, id)
+  WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS task_comment_mentions (
+  comment_id  BIGINT NOT NULL REFERENCES task_comments(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  user_id     BIGINT NOT NULL REFERENCES users(id)         ON UPDATE CASCADE ON DELETE CASCADE,
+  PRIMARY KEY (comment_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_comment_mentions_user
+  ON task_comment_mentions (user_id);
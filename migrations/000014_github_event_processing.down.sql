@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_github_event_log_claim;
+
+ALTER TABLE github_event_log
+  DROP CONSTRAINT IF EXISTS github_event_log_status_check;
+
+ALTER TABLE github_event_log
+  DROP COLUMN IF EXISTS processed_at,
+  DROP COLUMN IF EXISTS next_attempt_at,
+  DROP COLUMN IF EXISTS last_error,
+  DROP COLUMN IF EXISTS attempts,
+  DROP COLUMN IF EXISTS status;
@@ -0,0 +1,18 @@
+ALTER TABLE github_event_log
+  ADD COLUMN IF NOT EXISTS status          TEXT        NOT NULL DEFAULT 'pending',
+  ADD COLUMN IF NOT EXISTS attempts        INT         NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS last_error      TEXT,
+  ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  ADD COLUMN IF NOT EXISTS processed_at    TIMESTAMPTZ;
+
+-- Everything logged before this migration was processed inline
+UPDATE github_event_log
+SET status = 'done', processed_at = received_at;
+
+ALTER TABLE github_event_log
+  ADD CONSTRAINT github_event_log_status_check
+    CHECK (status IN ('pending','processing','done','ignored','failed'));
+
+CREATE INDEX IF NOT EXISTS idx_github_event_log_claim
+  ON github_event_log (next_attempt_at)
+  WHERE status IN ('pending','processing');
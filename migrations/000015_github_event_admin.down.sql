@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_github_event_log_repo;
+DROP INDEX IF EXISTS idx_github_event_log_received;
+
+ALTER TABLE github_event_log
+  DROP COLUMN IF EXISTS tasks_updated;
@@ -0,0 +1,8 @@
+ALTER TABLE github_event_log
+  ADD COLUMN IF NOT EXISTS tasks_updated INT;
+
+CREATE INDEX IF NOT EXISTS idx_github_event_log_received
+  ON github_event_log (received_at DESC, delivery_id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_github_event_log_repo
+  ON github_event_log ((payload->'repository'->>'full_name'));
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_tasks_repo_pr_number;
+
+ALTER TABLE tasks
+  DROP COLUMN IF EXISTS pr_draft,
+  DROP COLUMN IF EXISTS pr_state,
+  DROP COLUMN IF EXISTS pr_url;
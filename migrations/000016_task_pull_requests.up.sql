@@ -0,0 +1,8 @@
+ALTER TABLE tasks
+  ADD COLUMN IF NOT EXISTS pr_url   TEXT,
+  ADD COLUMN IF NOT EXISTS pr_state TEXT CHECK (pr_state IN ('open','closed','merged')),
+  ADD COLUMN IF NOT EXISTS pr_draft BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_repo_pr_number
+  ON tasks (repo_full_name, pr_number)
+  WHERE pr_number IS NOT NULL;
@@ -0,0 +1,22 @@
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS task_status_format_check;
+
+-- Fold custom statuses back into the fixed set before restoring the check
+UPDATE tasks t
+SET status = CASE ps.category
+  WHEN 'todo'  THEN 'todo'
+  WHEN 'doing' THEN 'in_progress'
+  ELSE 'done'
+END
+FROM project_statuses ps
+WHERE ps.project_id = t.project_id
+  AND ps.key = t.status
+  AND t.status NOT IN ('todo','in_progress','done');
+
+UPDATE tasks SET status = 'todo'
+WHERE status NOT IN ('todo','in_progress','done');
+
+ALTER TABLE tasks ADD CONSTRAINT task_status_check
+  CHECK (status IN ('todo','in_progress','done'));
+
+DROP INDEX IF EXISTS uq_project_statuses_key;
+DROP TABLE IF EXISTS project_statuses;
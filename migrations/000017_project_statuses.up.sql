@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS project_statuses (
+  id          BIGSERIAL PRIMARY KEY,
+  project_id  BIGINT NOT NULL REFERENCES projects(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  key         TEXT   NOT NULL CHECK (key ~ '^[a-z][a-z0-9_]{0,31}$'),
+  label       TEXT   NOT NULL,
+  position    INT    NOT NULL,
+  category    TEXT   NOT NULL CHECK (category IN ('todo','doing','done'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_project_statuses_key
+  ON project_statuses (project_id, key);
+
+-- Statuses are validated against each project's workflow in the app now;
+-- projects without rows use todo / in_progress / done.
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS task_status_check;
+
+ALTER TABLE tasks
+  ADD CONSTRAINT task_status_format_check
+    CHECK (status ~ '^[a-z][a-z0-9_]{0,31}$');
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS task_checklist_items;
+
+DROP INDEX IF EXISTS idx_tasks_parent_task_id;
+
+ALTER TABLE tasks
+  DROP CONSTRAINT IF EXISTS task_parent_not_self,
+  DROP COLUMN IF EXISTS auto_complete,
+  DROP COLUMN IF EXISTS parent_task_id;
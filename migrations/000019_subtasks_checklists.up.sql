@@ -0,0 +1,22 @@
+ALTER TABLE tasks
+  ADD COLUMN IF NOT EXISTS parent_task_id BIGINT REFERENCES tasks(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  ADD COLUMN IF NOT EXISTS auto_complete  BOOLEAN NOT NULL DEFAULT false,
+  ADD CONSTRAINT task_parent_not_self CHECK (parent_task_id IS NULL OR parent_task_id <> id);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_parent_task_id
+  ON tasks (parent_task_id)
+  WHERE parent_task_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS task_checklist_items (
+  id          BIGSERIAL PRIMARY KEY,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  task_id     BIGINT  NOT NULL REFERENCES tasks(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  body        TEXT    NOT NULL,
+  done        BOOLEAN NOT NULL DEFAULT false,
+  position    DOUBLE PRECISION NOT NULL DEFAULT 1000
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_checklist_items_task
+  ON task_checklist_items (task_id, position);
@@ -0,0 +1,5 @@
+ALTER TABLE github_event_log DROP COLUMN IF EXISTS tasks_unblocked;
+
+ALTER TABLE projects DROP COLUMN IF EXISTS blocked_policy;
+
+DROP TABLE IF EXISTS task_dependencies;
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS task_dependencies (
+  blocker_id     BIGINT NOT NULL REFERENCES tasks(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  blocked_id     BIGINT NOT NULL REFERENCES tasks(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by_id  BIGINT REFERENCES users(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  PRIMARY KEY (blocker_id, blocked_id),
+  CONSTRAINT task_dependency_not_self CHECK (blocker_id <> blocked_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_dependencies_blocked
+  ON task_dependencies (blocked_id);
+
+-- What happens when a blocked task is started: refuse the change, or allow
+-- it and return a warning
+ALTER TABLE projects
+  ADD COLUMN IF NOT EXISTS blocked_policy TEXT NOT NULL DEFAULT 'refuse'
+    CHECK (blocked_policy IN ('refuse','warn'));
+
+ALTER TABLE github_event_log
+  ADD COLUMN IF NOT EXISTS tasks_unblocked JSONB;
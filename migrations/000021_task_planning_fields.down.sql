@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_tasks_due_at_open;
+
+ALTER TABLE tasks
+  DROP COLUMN IF EXISTS estimate_points,
+  DROP COLUMN IF EXISTS priority,
+  DROP COLUMN IF EXISTS due_at;
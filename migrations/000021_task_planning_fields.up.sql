@@ -0,0 +1,9 @@
+ALTER TABLE tasks
+  ADD COLUMN IF NOT EXISTS due_at          TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS priority        TEXT CHECK (priority IN ('low','medium','high','urgent')),
+  ADD COLUMN IF NOT EXISTS estimate_points INT  CHECK (estimate_points BETWEEN 1 AND 1000);
+
+-- Serves the overdue query: open tasks with a due date
+CREATE INDEX IF NOT EXISTS idx_tasks_due_at_open
+  ON tasks (due_at)
+  WHERE due_at IS NOT NULL AND completed_at IS NULL AND deleted_at IS NULL;
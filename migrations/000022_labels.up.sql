@@ -0,0 +1,44 @@
+CREATE TABLE IF NOT EXISTS labels (
+  id          BIGSERIAL PRIMARY KEY,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  project_id  BIGINT NOT NULL REFERENCES projects(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  name        TEXT   NOT NULL CHECK (length(name) BETWEEN 1 AND 50),
+  color       TEXT   NOT NULL DEFAULT '#6b7280' CHECK (color ~ '^#[0-9a-f]{6}$')
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_labels_project_name
+  ON labels (project_id, lower(name));
+
+CREATE TABLE IF NOT EXISTS task_labels (
+  task_id   BIGINT NOT NULL REFERENCES tasks(id)  ON UPDATE CASCADE ON DELETE CASCADE,
+  label_id  BIGINT NOT NULL REFERENCES labels(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  PRIMARY KEY (task_id, label_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_labels_label
+  ON task_labels (label_id);
+
+-- Carry the old fixed tags over as labels on project tasks. Personal tasks
+-- have no project to own a label and keep using tag.
+INSERT INTO labels (project_id, name, color)
+SELECT DISTINCT t.project_id, t.tag,
+       CASE t.tag
+         WHEN 'feature'         THEN '#2563eb'
+         WHEN 'feature_request' THEN '#7c3aed'
+         WHEN 'issue'           THEN '#dc2626'
+         ELSE '#6b7280'
+       END
+FROM tasks t
+WHERE t.project_id IS NOT NULL
+  AND t.tag IS NOT NULL AND t.tag <> ''
+  AND t.deleted_at IS NULL
+ON CONFLICT DO NOTHING;
+
+INSERT INTO task_labels (task_id, label_id)
+SELECT t.id, l.id
+FROM tasks t
+JOIN labels l ON l.project_id = t.project_id AND lower(l.name) = lower(t.tag)
+WHERE t.tag IS NOT NULL AND t.tag <> ''
+ON CONFLICT DO NOTHING;
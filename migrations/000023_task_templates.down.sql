@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS uq_tasks_template_run;
+
+ALTER TABLE tasks
+  DROP COLUMN IF EXISTS template_run_at,
+  DROP COLUMN IF EXISTS template_id;
+
+DROP TABLE IF EXISTS task_templates;
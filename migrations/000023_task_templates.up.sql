@@ -0,0 +1,42 @@
+CREATE TABLE IF NOT EXISTS task_templates (
+  id               BIGSERIAL PRIMARY KEY,
+  created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  project_id       BIGINT NOT NULL REFERENCES projects(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  creator_id       BIGINT NOT NULL REFERENCES users(id)    ON UPDATE CASCADE ON DELETE RESTRICT,
+
+  -- Copied onto every task the template creates
+  title            TEXT   NOT NULL,
+  description      TEXT   NOT NULL DEFAULT '',
+  assignee_id      BIGINT REFERENCES users(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  priority         TEXT   CHECK (priority IN ('low','medium','high','urgent')),
+  estimate_points  INT    CHECK (estimate_points BETWEEN 1 AND 1000),
+  label_ids        JSONB,
+  due_in_days      INT    CHECK (due_in_days BETWEEN 0 AND 365),
+
+  -- "daily", "weekly", "monthly" or a five-field cron expression,
+  -- evaluated in timezone
+  recurrence       TEXT   NOT NULL,
+  timezone         TEXT   NOT NULL DEFAULT 'UTC',
+  enabled          BOOLEAN NOT NULL DEFAULT true,
+  next_run_at      TIMESTAMPTZ NOT NULL,
+  last_run_at      TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_templates_project
+  ON task_templates (project_id);
+
+-- Serves the scheduler's due query
+CREATE INDEX IF NOT EXISTS idx_task_templates_next_run
+  ON task_templates (next_run_at)
+  WHERE enabled;
+
+ALTER TABLE tasks
+  ADD COLUMN IF NOT EXISTS template_id     BIGINT REFERENCES task_templates(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  ADD COLUMN IF NOT EXISTS template_run_at TIMESTAMPTZ;
+
+-- One task per template run, however many replicas try to create it
+CREATE UNIQUE INDEX IF NOT EXISTS uq_tasks_template_run
+  ON tasks (template_id, template_run_at)
+  WHERE template_id IS NOT NULL;
@@ -0,0 +1,8 @@
+ALTER TABLE projects
+  DROP COLUMN IF EXISTS sync_issue_state;
+
+DROP INDEX IF EXISTS idx_tasks_repo_issue;
+DROP INDEX IF EXISTS uq_tasks_project_issue;
+
+ALTER TABLE tasks
+  DROP COLUMN IF EXISTS issue_number;
@@ -0,0 +1,16 @@
+-- Tasks imported from a GitHub issue remember it so re-imports skip them and
+-- issues webhooks can find them
+ALTER TABLE tasks
+  ADD COLUMN IF NOT EXISTS issue_number INT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_tasks_project_issue
+  ON tasks (project_id, repo_full_name, issue_number)
+  WHERE issue_number IS NOT NULL AND deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_repo_issue
+  ON tasks (repo_full_name, issue_number)
+  WHERE issue_number IS NOT NULL;
+
+-- Close/reopen linked issues when their tasks are completed/reopened
+ALTER TABLE projects
+  ADD COLUMN IF NOT EXISTS sync_issue_state BOOLEAN NOT NULL DEFAULT false;
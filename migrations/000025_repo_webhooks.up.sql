@@ -0,0 +1,15 @@
+-- Webhooks Hydianflow installed itself, each signed with its own secret.
+-- Repositories without a row keep using GITHUB_WEBHOOK_SECRET.
+CREATE TABLE IF NOT EXISTS repo_webhooks (
+  id              BIGSERIAL PRIMARY KEY,
+  created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  repo_full_name  TEXT   NOT NULL,
+  secret          TEXT   NOT NULL,
+  hook_id         BIGINT NOT NULL,
+  installed_by_id BIGINT REFERENCES users(id) ON UPDATE CASCADE ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_repo_webhooks_repo
+  ON repo_webhooks (lower(repo_full_name));
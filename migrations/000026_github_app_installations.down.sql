@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS github_app_repos;
+DROP TABLE IF EXISTS github_app_installations;
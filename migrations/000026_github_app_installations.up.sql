@@ -0,0 +1,21 @@
+-- GitHub App installations and the repositories they grant access to, kept
+-- up to date by the installation and installation_repositories webhooks
+CREATE TABLE IF NOT EXISTS github_app_installations (
+  id             BIGINT PRIMARY KEY,
+  created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  account_login  TEXT NOT NULL DEFAULT '',
+  suspended_at   TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS github_app_repos (
+  repo_full_name   TEXT   PRIMARY KEY,
+  installation_id  BIGINT NOT NULL REFERENCES github_app_installations(id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_github_app_repos_lower
+  ON github_app_repos (lower(repo_full_name));
+
+CREATE INDEX IF NOT EXISTS idx_github_app_repos_installation
+  ON github_app_repos (installation_id);
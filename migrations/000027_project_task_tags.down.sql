@@ -0,0 +1,3 @@
+-- Tags moved onto labels stay there
+ALTER TABLE tasks
+  DROP CONSTRAINT IF EXISTS tasks_tag_personal_check;
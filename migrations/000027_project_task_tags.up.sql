@@ -0,0 +1,29 @@
+-- Project tasks use labels only. Carry over any tag set since 000022 the
+-- same way, then keep tags to personal tasks.
+INSERT INTO labels (project_id, name, color)
+SELECT DISTINCT t.project_id, t.tag,
+       CASE t.tag
+         WHEN 'feature'         THEN '#2563eb'
+         WHEN 'feature_request' THEN '#7c3aed'
+         WHEN 'issue'           THEN '#dc2626'
+         ELSE '#6b7280'
+       END
+FROM tasks t
+WHERE t.project_id IS NOT NULL
+  AND t.tag IS NOT NULL AND t.tag <> ''
+  AND t.deleted_at IS NULL
+ON CONFLICT DO NOTHING;
+
+INSERT INTO task_labels (task_id, label_id)
+SELECT t.id, l.id
+FROM tasks t
+JOIN labels l ON l.project_id = t.project_id AND lower(l.name) = lower(t.tag)
+WHERE t.tag IS NOT NULL AND t.tag <> ''
+ON CONFLICT DO NOTHING;
+
+UPDATE tasks
+SET tag = NULL
+WHERE project_id IS NOT NULL AND tag IS NOT NULL;
+
+ALTER TABLE tasks
+  ADD CONSTRAINT tasks_tag_personal_check CHECK (project_id IS NULL OR tag IS NULL);